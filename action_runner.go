@@ -1,12 +1,14 @@
 package application
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"github.com/pasztorpisti/qs"
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 	"regexp"
 )
 
@@ -16,6 +18,9 @@ type ActionRunner struct {
 	logger     Logger
 	jsonWriter JsonResponseWriter
 	router     Router
+	validator  StructValidator
+	codecs     *CodecRegistry
+	errorMap   *ErrorRegistry
 }
 
 type ActionResponse struct {
@@ -39,8 +44,22 @@ func NewSuccessCreationResponse(response any) ActionResponse {
 	}
 }
 
-func NewActionRunner(logger Logger, jsonWriter JsonResponseWriter, router Router) *ActionRunner {
-	return &ActionRunner{logger: logger, jsonWriter: jsonWriter, router: router}
+func NewActionRunner(
+	logger Logger,
+	jsonWriter JsonResponseWriter,
+	router Router,
+	validator StructValidator,
+	codecs *CodecRegistry,
+	errorMap *ErrorRegistry,
+) *ActionRunner {
+	return &ActionRunner{
+		logger:     logger,
+		jsonWriter: jsonWriter,
+		router:     router,
+		validator:  validator,
+		codecs:     codecs,
+		errorMap:   errorMap,
+	}
 }
 
 func (j *ActionRunner) Run(
@@ -49,8 +68,14 @@ func (j *ActionRunner) Run(
 	action func(ctx context.Context, request any) ActionResponse,
 	request any,
 ) {
+	if accept := r.Header.Get("Accept"); accept != "" {
+		if _, ok := j.codecs.Negotiate(accept); !ok {
+			j.jsonWriter.Error(w, r, NewNotAcceptableResponse(r.Context(), accept))
+			return
+		}
+	}
 	switch r.Method {
-	case http.MethodGet, http.MethodDelete:
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
 		j.runGet(w, r, action, request)
 	case http.MethodPost:
 		j.runPost(w, r, action, request)
@@ -88,10 +113,14 @@ func (j *ActionRunner) runPost(
 	var err error
 	err = j.fillRequestFromUrlValues(w, r, request, j.router.RouteParams(r))
 	if err == nil {
-		if r.Header.Get("Content-Type") == "application/json" {
+		switch requestMediaType(r) {
+		case MultipartMediaType:
+			err = j.fillRequestFromMultipartForm(w, r, request)
+			defer removeMultipartFiles(r)
+		case "", FormMediaType:
+			err = j.fillRequestFromForm(w, r, request)
+		default:
 			err = j.fillRequestFromBody(w, r, request)
-		} else {
-			err = j.fillRequestFromUrlValues(w, r, request, r.PostForm)
 		}
 	}
 
@@ -110,7 +139,18 @@ func (j *ActionRunner) runPut(
 	var err error
 
 	err = j.fillRequestFromUrlValues(w, r, request, j.router.RouteParams(r))
-	err = j.fillRequestFromBody(w, r, request)
+	if err == nil {
+		switch requestMediaType(r) {
+		case "":
+		case MultipartMediaType:
+			err = j.fillRequestFromMultipartForm(w, r, request)
+			defer removeMultipartFiles(r)
+		case FormMediaType:
+			err = j.fillRequestFromForm(w, r, request)
+		default:
+			err = j.fillRequestFromBody(w, r, request)
+		}
+	}
 
 	if err != nil {
 		return
@@ -124,12 +164,12 @@ func (j *ActionRunner) runAction(
 	action func(ctx context.Context, request any) ActionResponse,
 	request any,
 ) {
-	if validator, ok := request.(ValidatableStruct); ok {
-		validationErr := validator.Validate(r.Context())
-		if validationErr != nil {
-			j.jsonWriter.Error(w, r, NewValidationErrorResponse(r.Context(), validationErr))
-			return
-		}
+	if err := j.fillRequestFromParams(w, r, request); err != nil {
+		return
+	}
+	if validationErrors := j.validate(r.Context(), request); len(validationErrors) > 0 {
+		j.jsonWriter.Error(w, r, NewValidationErrorResponse(r.Context(), validationErrors))
+		return
 	}
 
 	response := action(r.Context(), request)
@@ -141,6 +181,29 @@ func (j *ActionRunner) runAction(
 	j.jsonWriter.Success(w, r, response)
 }
 
+// validate checks the request by validate tags and then by its own Validate method if it is ValidatableStruct
+func (j *ActionRunner) validate(ctx context.Context, request any) []ValidationError {
+	var validationErrors []ValidationError
+	if j.validator != nil && isStruct(request) {
+		validationErrors = append(validationErrors, j.validator.ValidateStruct(request)...)
+	}
+	if validator, ok := request.(ValidatableStruct); ok {
+		validationErrors = append(validationErrors, validator.Validate(ctx)...)
+	}
+	return validationErrors
+}
+
+func isStruct(obj any) bool {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Struct
+}
+
 func (j *ActionRunner) fillRequestFromBody(
 	w http.ResponseWriter,
 	r *http.Request,
@@ -149,8 +212,11 @@ func (j *ActionRunner) fillRequestFromBody(
 	if request == nil {
 		return nil
 	}
-	if r.Header.Get("Content-Type") != "application/json" {
-		return nil
+	codec, ok := j.codecs.Decoder(r.Header.Get("Content-Type"))
+	if !ok {
+		err := errors.New("unsupported content type")
+		j.jsonWriter.Error(w, r, NewUnsupportedMediaTypeResponse(r.Context(), r.Header.Get("Content-Type")))
+		return err
 	}
 	var err error
 	defer r.Body.Close()
@@ -158,9 +224,15 @@ func (j *ActionRunner) fillRequestFromBody(
 	var body []byte
 
 	body, err = io.ReadAll(r.Body)
+	if err != nil {
+		j.jsonWriter.Error(w, r, j.parseFormError(r.Context(), err))
+		return err
+	}
 
-	if err == nil && body != nil {
-		err = json.Unmarshal(body, request)
+	if len(body) > 0 {
+		restore := keepParamFields(request)
+		err = codec.Decode(bytes.NewReader(body), request)
+		restore()
 	}
 	if err != nil {
 		j.jsonWriter.Error(w, r, NewServerErrorResponse(r.Context(), WrongRequestDecoding, err))
@@ -179,7 +251,7 @@ func (j *ActionRunner) fillRequestFromUrlValues(
 	if request == nil {
 		return nil
 	}
-	err := qs.Unmarshal(request, values.Encode())
+	err := qs.Unmarshal(request, withoutBoundParams(request, values).Encode())
 	if err != nil {
 		resp := j.parseQsError(r.Context(), err)
 		j.jsonWriter.Error(w, r, resp)
@@ -200,3 +272,76 @@ func (j *ActionRunner) parseQsError(ctx context.Context, err error) ActionRespon
 	}
 	return NewValidationErrorResponse(ctx, []ValidationError{*vErr})
 }
+
+// Handle adapts a typed handler to http.HandlerFunc. A fresh request is allocated for each call,
+// filled from the http request and validated by the runner. A returned error is converted to an error response
+// by ActionRunner.ErrorResponse.
+func Handle[Req any, Resp any](
+	runner *ActionRunner,
+	handler func(ctx context.Context, request *Req) (Resp, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		runner.Run(
+			w,
+			r,
+			func(ctx context.Context, request any) ActionResponse {
+				response, err := handler(ctx, request.(*Req))
+				if err != nil {
+					return runner.ErrorResponse(ctx, err)
+				}
+				return NewSuccessResponse(response)
+			},
+			new(Req),
+		)
+	}
+}
+
+// DocumentedHandler is a handler together with the documentation of its request and response
+type DocumentedHandler struct {
+	Handler http.HandlerFunc
+	Doc     RouteDoc
+}
+
+// HandleDocumented adapts a typed handler the same way as Handle does and records types of the request
+// and the response for the OpenAPI document. The result is added to routes by Routes.Handle
+func HandleDocumented[Req any, Resp any](
+	runner *ActionRunner,
+	handler func(ctx context.Context, request *Req) (Resp, error),
+) DocumentedHandler {
+	return DocumentedHandler{
+		Handler: Handle(runner, handler),
+		Doc: RouteDoc{
+			Request:   new(Req),
+			Responses: map[int]any{http.StatusOK: *new(Resp)},
+		},
+	}
+}
+
+// ErrorResponse converts an error returned by an action to the response by the ErrorRegistry
+// or by NewErrorResponse if the error is not registered
+func (j *ActionRunner) ErrorResponse(ctx context.Context, err error) ActionResponse {
+	if j.errorMap != nil {
+		if response, ok := j.errorMap.Response(ctx, err); ok {
+			return response
+		}
+	}
+	return NewErrorResponse(ctx, err)
+}
+
+// HandleAction adapts the action to http.HandlerFunc the same way as Handle does
+func (j *ActionRunner) HandleAction(action Action) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		j.Run(
+			w,
+			r,
+			func(ctx context.Context, request any) ActionResponse {
+				response, err := action.Handle(ctx, request)
+				if err != nil {
+					return j.ErrorResponse(ctx, err)
+				}
+				return NewSuccessResponse(response)
+			},
+			action.NewRequestObject(),
+		)
+	}
+}
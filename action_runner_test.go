@@ -0,0 +1,111 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type handleTestRequest struct {
+	Id   int    `qs:"id" json:"-"`
+	Name string `qs:"-" json:"name"`
+}
+
+type handleTestResponse struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newTestRunner(router Router) *ActionRunner {
+	logger := NewDefaultLogger()
+	codecs := NewCodecRegistry()
+	return NewActionRunner(
+		logger,
+		NewJsonResponseWriter(logger, NewConfig(), codecs),
+		router,
+		NewDefaultValidator(logger),
+		codecs,
+		NewErrorRegistry(),
+	)
+}
+
+func TestHandle(t *testing.T) {
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Put("/users/{id}", Handle(
+		runner,
+		func(ctx context.Context, request *handleTestRequest) (handleTestResponse, error) {
+			if request.Name == "" {
+				return handleTestResponse{}, NewValidationError("name", "Name is required", InvalidRequest)
+			}
+			return handleTestResponse{Id: request.Id, Name: request.Name}, nil
+		},
+	))
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/users/7", strings.NewReader(`{"name":"John"}`))
+	r.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":7,"name":"John"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPut, "/users/7", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"name"`)
+}
+
+func TestNewErrorResponse(t *testing.T) {
+	ctx := context.Background()
+	response := NewErrorResponse(ctx, ValidationErrors{*NewValidationError("a", "wrong", InvalidRequest)})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Len(t, response.Error.ValidationErrors, 1)
+
+	response = NewErrorResponse(ctx, NewCommonError("UserExists", "user exists"))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+
+	response = NewErrorResponse(ctx, errors.New("db is down"))
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+}
+
+type validatedTestRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"omitempty,email"`
+}
+
+func (v *validatedTestRequest) Validate(ctx context.Context) []ValidationError {
+	if v.Email == "admin@example.com" {
+		return []ValidationError{*NewValidationError("email", "Email is reserved", InvalidRequest)}
+	}
+	return nil
+}
+
+func TestRunValidatesRequest(t *testing.T) {
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Post("/users", Handle(
+		runner,
+		func(ctx context.Context, request *validatedTestRequest) (*validatedTestRequest, error) {
+			return request, nil
+		},
+	))
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"email":"admin@example.com"}`))
+	r.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"name"`)
+	assert.Contains(t, w.Body.String(), `"message":"Email is reserved"`)
+}
@@ -2,17 +2,60 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/joho/godotenv"
 	"go.uber.org/dig"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"reflect"
+	"strings"
+	"sync"
+	"syscall"
 )
 
+// ErrShutdownTimeout is returned by Run when close listeners do not finish in the shutdown timeout
+var ErrShutdownTimeout = errors.New("application shutdown timeout exceeded")
+
+// Shutdowner allows modules to stop the running application,
+// for example when a web-server cannot listen its port
+type Shutdowner interface {
+	// Shutdown stops the application. A non-nil err is returned from Application.Run
+	Shutdown(err error)
+}
+
+// Fataler allows modules to terminate the process on an unrecoverable error
+// after the close listeners of all modules are called
+type Fataler interface {
+	// Fatal stops the application, calls close listeners and exits the process with the status code 1
+	Fatal(err error)
+}
+
 type Application struct {
 	container     *dig.Container
 	moduleConfigs []interface{}
+	config        *Config
+	routePrefixes map[reflect.Type]string
+	openApiPath   string
+	openApiInfo   OpenApiInfo
+
+	ctx          context.Context
+	cancel       context.CancelFunc
+	shutdownOnce sync.Once
+	shutdownErr  error
+	configErr    error
+	closeOnce    sync.Once
+	closeErr     error
+	exit         func(code int)
+	lifecycle    sync.Mutex
+	starting     bool
+	serving      bool
+	fatal        bool
+	started      int
+	runDone      chan struct{}
 }
 
 func (a *Application) Container() *dig.Container {
@@ -21,33 +64,229 @@ func (a *Application) Container() *dig.Container {
 
 func New(moduleConfigs []interface{}) *Application {
 	container := dig.New()
+	ctx, cancel := context.WithCancel(context.Background())
 	app := &Application{
-		container: container,
+		container:     container,
+		ctx:           ctx,
+		cancel:        cancel,
+		routePrefixes: make(map[reflect.Type]string),
+		exit:          os.Exit,
+		runDone:       make(chan struct{}),
 	}
 	app.readEnv()
 
-	applicationConfig := NewConfig()
+	sources, configErr := defaultConfigSources("")
+	app.configErr = configErr
+	applicationConfig := NewConfig(sources...)
+	if dir, exists := applicationConfig.LookupEnv("APP_SECRETS_DIR"); exists && dir != "" {
+		applicationConfig.AddSecretProvider(NewSecretsDirSource(dir))
+	}
+	app.config = applicationConfig
 
-	app.moduleConfigs = append(moduleConfigs, applicationConfig)
+	sortedConfigs, err := sortModules(append(moduleConfigs, applicationConfig))
+	if err != nil {
+		panic(err)
+	}
+	app.moduleConfigs = sortedConfigs
+	err = container.Provide(func() Shutdowner { return app })
+	if err != nil {
+		panic(err)
+	}
+	err = container.Provide(func() Fataler { return app })
+	if err != nil {
+		panic(err)
+	}
 	app.fillProvidedServices()
+	applicationConfig.DeclareKeys(app.ConfigSchema()...)
 
 	return app
 }
 
-func (a *Application) Run() error {
-	a.initConfig()
+// Context returns the root context of the application. It is cancelled when the application is stopping
+func (a *Application) Context() context.Context {
+	return a.ctx
+}
 
-	a.setDefaultLogger()
-	a.setDefaultJsonResponseWriter()
-	a.setDefaultValidator()
+// Shutdown stops the running application. Only the first call has an effect
+func (a *Application) Shutdown(err error) {
+	a.shutdownOnce.Do(func() {
+		a.shutdownErr = err
+		a.cancel()
+	})
+}
+
+// Fatal logs the error, stops the application, then exits the process with the status code 1.
+// If Run is already running services, Fatal waits within the shutdown timeout until Run stops them and calls
+// close listeners. If modules are still starting, for example when Fatal is called from OnStart of a module,
+// no more modules are started and Fatal returns at once, so OnStart can return. Then Run calls close listeners
+// and the process exits after Run stops.
+// Otherwise Fatal calls close listeners itself if they are not called yet
+func (a *Application) Fatal(err error) {
+	logger := a.getLogger()
+	logger.Error(a.ctx, "Fatal error: %s", err)
+	a.Shutdown(err)
+	a.lifecycle.Lock()
+	a.fatal = true
+	starting, serving := a.starting, a.serving
+	a.lifecycle.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout())
+	switch {
+	case starting:
+		go func() {
+			defer cancel()
+			a.waitRun(ctx)
+			a.exit(1)
+		}()
+		return
+	case serving:
+		a.waitRun(ctx)
+	default:
+		if closeErr := a.close(ctx); closeErr != nil {
+			logger.Error(a.ctx, "Application closing failed: %s", closeErr)
+		}
+	}
+	cancel()
+	a.exit(1)
+}
 
-	a.initHttpRoutes()
+// waitRun waits until Run stops services and calls close listeners or until ctx is done
+func (a *Application) waitRun(ctx context.Context) {
+	select {
+	case <-a.runDone:
+	case <-ctx.Done():
+		a.getLogger().Error(a.ctx, "Application closing failed: %s", ErrShutdownTimeout)
+	}
+}
 
-	a.onStart()
-	defer a.onClose()
+// SetExitFunc replaces os.Exit called by Fatal, for example to keep a test process running
+func (a *Application) SetExitFunc(exit func(code int)) {
+	a.exit = exit
+}
+
+// Config returns the application config. Sources added to it before Run are visible to all modules
+func (a *Application) Config() *Config {
+	return a.config
+}
+
+// ConfigSchema returns config keys declared by all modules
+func (a *Application) ConfigSchema() []ConfigKey {
+	var keys []ConfigKey
+	for _, moduleConfig := range a.moduleConfigs {
+		if schemaProvider, ok := moduleConfig.(ConfigSchemaProvider); ok {
+			keys = append(keys, schemaProvider.ConfigSchema()...)
+		}
+	}
+	return keys
+}
+
+// WriteConfigReport writes the effective values of config keys declared by modules grouped by modules.
+// Secret values are redacted
+func (a *Application) WriteConfigReport(w io.Writer) error {
+	for _, moduleConfig := range a.moduleConfigs {
+		schemaProvider, ok := moduleConfig.(ConfigSchemaProvider)
+		if !ok {
+			continue
+		}
+		if _, err := io.WriteString(w, "# "+ModuleName(moduleConfig)+"\n"); err != nil {
+			return err
+		}
+		if err := a.config.WriteConfigReport(w, schemaProvider.ConfigSchema()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// MountModule makes all routes of the module to be served under the prefix, for example "/api/v1/billing".
+// It should be called before Run
+func (a *Application) MountModule(moduleConfig interface{}, prefix string) {
+	a.routePrefixes[moduleType(moduleConfig)] = prefix
+}
+
+// EnableOpenApi makes the router serve the OpenAPI document of all module routes on the path,
+// for example "/openapi.json". It should be called before Run
+func (a *Application) EnableOpenApi(path string, info OpenApiInfo) {
+	a.openApiPath = path
+	a.openApiInfo = info
+}
+
+// OpenApi initializes the configuration of modules and generates the OpenAPI document of all their routes
+// without running the application. It allows writing the document to a file, for example in a go:generate command
+func (a *Application) OpenApi(info OpenApiInfo) (*OpenApiDocument, error) {
+	a.setDefaults()
+
+	if err := a.initConfig(a.ctx); err != nil {
+		return nil, err
+	}
+	var allRoutes []RouteInfo
+	err := a.collectRoutes(func(routes []RouteInfo) {
+		allRoutes = append(allRoutes, routes...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewOpenApiDocument(info, allRoutes), nil
+}
+
+// Run initializes and starts all modules, then runs their services concurrently until the application receives
+// SIGINT or SIGTERM, until Shutdown is called or until any service fails. After that all close listeners are called
+// within the shutdown timeout of the application config.
+// Errors of all modules are joined and returned as ModuleError values.
+func (a *Application) Run() error {
+	defer close(a.runDone)
+	a.setDefaults()
+	// from now on Fatal leaves calling close listeners to Run
+	a.setStarting(true)
+	defer a.setStarting(false)
+
+	if err := a.initConfig(a.ctx); err != nil {
+		return err
+	}
+	a.printConfig(a.ctx)
+	if err := a.initHttpRoutes(); err != nil {
+		return err
+	}
+
+	var supervisor *Supervisor
+	var serviceErr error
+	startErr := a.onStart(a.ctx)
+	// signals are handled only after modules are started, so a module blocking in OnStart
+	// keeps the default behaviour of SIGINT and SIGTERM that terminates the process
+	ctx, stop := signal.NotifyContext(a.ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if a.startServing(startErr) {
+		supervisor, serviceErr = a.runServices(ctx)
+	}
+	a.Shutdown(nil)
+	stop()
+
+	// services and close listeners share one shutdown timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout())
+	defer cancel()
+	if supervisor != nil {
+		serviceErr = supervisor.Stop(shutdownCtx)
+	}
+	closeErr := a.close(shutdownCtx)
+	return errors.Join(startErr, serviceErr, a.shutdownErr, closeErr)
+}
+
+func (a *Application) setStarting(starting bool) {
+	a.lifecycle.Lock()
+	defer a.lifecycle.Unlock()
+	a.starting = starting
+}
+
+// startServing returns true if services should be run after modules are started.
+// It is false if a module failed to start or Fatal was called
+func (a *Application) startServing(startErr error) bool {
+	a.lifecycle.Lock()
+	defer a.lifecycle.Unlock()
+	a.starting = false
+	a.serving = startErr == nil && !a.fatal
+	return a.serving
+}
+
 func (a *Application) fillProvidedServices() {
 	for _, moduleConfig := range a.moduleConfigs {
 		if containerHolder, ok := moduleConfig.(ContainerHolder); ok {
@@ -66,17 +305,56 @@ func (a *Application) fillProvidedServices() {
 	}
 }
 
-func (a *Application) initConfig() {
-	config := NewConfig()
+func (a *Application) initConfig(ctx context.Context) error {
+	if a.configErr != nil {
+		return a.configErr
+	}
+	if err := a.validateConfig(); err != nil {
+		return err
+	}
+	if _, err := a.config.LogLevel(); err != nil {
+		return err
+	}
+	config := a.config
 	for _, serviceProvider := range a.moduleConfigs {
-		if routesContainer, ok := serviceProvider.(ConfigInitializer); ok {
-			err := routesContainer.InitConfig(*config)
-			if err != nil {
-				logger := a.getLogger()
-				logger.Panic(context.Background(), "Init config error: "+err.Error())
+		var err error
+		switch initializer := serviceProvider.(type) {
+		case ConfigInitializerWithContext:
+			err = initializer.InitConfig(ctx, *config)
+		case ConfigInitializer:
+			err = initializer.InitConfig(*config)
+		}
+		if err != nil {
+			return NewModuleError(serviceProvider, "config initialisation", err)
+		}
+	}
+	return config.ReadErrors()
+}
+
+// validateConfig checks keys declared by all modules and returns errors of all modules at once
+func (a *Application) validateConfig() error {
+	var errs []error
+	for _, moduleConfig := range a.moduleConfigs {
+		if schemaProvider, ok := moduleConfig.(ConfigSchemaProvider); ok {
+			if err := a.config.ValidateKeys(schemaProvider.ConfigSchema()); err != nil {
+				errs = append(errs, NewModuleError(moduleConfig, "config validation", err))
 			}
 		}
 	}
+	return errors.Join(errs...)
+}
+
+// printConfig logs the config report if the APP_PRINT_CONFIG variable is true
+func (a *Application) printConfig(ctx context.Context) {
+	if !a.config.GetEnvAsBoolOrDefault("APP_PRINT_CONFIG", false) {
+		return
+	}
+	report := &strings.Builder{}
+	if err := a.WriteConfigReport(report); err != nil {
+		a.getLogger().Error(ctx, "Config report cannot be written: %s", err)
+		return
+	}
+	a.getLogger().Info(ctx, "Effective config:\n"+report.String())
 }
 
 func (a *Application) readEnv() {
@@ -95,56 +373,180 @@ func (a *Application) readEnv() {
 	}
 }
 
-func (a *Application) initHttpRoutes() {
-	moduleName := ""
+func (a *Application) initHttpRoutes() error {
+	var router Router
+	err := a.container.Invoke(func(dep Router) {
+		router = dep
+	})
+	if err != nil {
+		return err
+	}
+	if router == nil {
+		return nil
+	}
+	var allRoutes []RouteInfo
+	err = a.collectRoutes(func(routes []RouteInfo) {
+		router.AddRoutes(routes)
+		allRoutes = append(allRoutes, routes...)
+	})
+	if err != nil {
+		return err
+	}
+	if a.openApiPath != "" {
+		return a.addOpenApiRoute(router, allRoutes)
+	}
+	return nil
+}
+
+// addOpenApiRoute adds the route of the OpenAPI document. Its conflict with a module route is returned as an error
+func (a *Application) addOpenApiRoute(router Router, routes []RouteInfo) (err error) {
 	defer func() {
-		if err := recover(); err != nil {
-			logger := a.getLogger()
-			logger.Panic(
-				context.Background(),
-				fmt.Sprint(moduleName, ": routes initialisation failed: ", err),
-			)
+		if r := recover(); r != nil {
+			err = fmt.Errorf("openapi route initialisation: %v", r)
 		}
 	}()
-	router := a.getRouter()
+	doc := NewOpenApiDocument(a.openApiInfo, routes)
+	router.AddRoutes([]RouteInfo{*NewRouteInfo(http.MethodGet, a.openApiPath, doc.Handler())})
+	return nil
+}
 
-	if router == nil {
-		return
+// collectRoutes passes routes of each module with applied prefixes and middlewares to the add function
+func (a *Application) collectRoutes(add func(routes []RouteInfo)) (err error) {
+	var current interface{}
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewModuleError(current, "routes initialisation", fmt.Errorf("%v", r))
+		}
+	}()
+	var globalMiddlewares []Middleware
+	err = a.container.Invoke(func(dep *GlobalMiddlewares, recoverer *Recoverer) {
+		globalMiddlewares = append([]Middleware{RequestIdMiddleware, recoverer.Middleware}, dep.Middlewares()...)
+	})
+	if err != nil {
+		return err
 	}
 	for _, serviceProvider := range a.moduleConfigs {
-		if t := reflect.TypeOf(serviceProvider); t.Kind() == reflect.Ptr {
-			moduleName = t.Elem().PkgPath()
-		}
+		current = serviceProvider
 		if routesContainer, ok := serviceProvider.(HttpRoutesInitializer); ok {
-			router.AddRoutes(routesContainer.ModuleRoutes())
+			var moduleMiddlewares []Middleware
+			if middlewaresContainer, ok := serviceProvider.(HttpMiddlewaresInitializer); ok {
+				moduleMiddlewares = middlewaresContainer.ModuleMiddlewares()
+			}
+			prefix := a.routePrefixes[moduleType(serviceProvider)]
+			routes := routesContainer.ModuleRoutes()
+			for i, route := range routes {
+				routes[i] = route.
+					WithPrefix(prefix).
+					WithMiddlewares(moduleMiddlewares...).
+					WithMiddlewares(moduleLogMiddleware(ModuleName(serviceProvider))).
+					WithMiddlewares(globalMiddlewares...)
+			}
+			add(routes)
+		}
+	}
+	return nil
+}
+
+// onStart starts modules in order and counts the started ones, so only they are closed.
+// Modules are not started after Fatal is called
+func (a *Application) onStart(ctx context.Context) error {
+	for _, serviceProvider := range a.moduleConfigs {
+		a.lifecycle.Lock()
+		fatal := a.fatal
+		a.lifecycle.Unlock()
+		if fatal {
+			return nil
 		}
+		var err error
+		switch appListener := serviceProvider.(type) {
+		case StartApplicationListenerWithContext:
+			err = appListener.OnStart(ctx)
+		case StartApplicationListener:
+			err = appListener.OnStart()
+		}
+		if err != nil {
+			return NewModuleError(serviceProvider, "start", err)
+		}
+		a.lifecycle.Lock()
+		a.started++
+		a.lifecycle.Unlock()
 	}
+	return nil
 }
 
-func (a *Application) onStart() {
+// runServices blocks until ctx is cancelled or any service fails. Services should be stopped by the returned supervisor
+func (a *Application) runServices(ctx context.Context) (*Supervisor, error) {
+	var supervisor *Supervisor
+	err := a.container.Invoke(func(dep *Supervisor) {
+		supervisor = dep
+	})
+	if err != nil {
+		return nil, err
+	}
+	if router, ok := a.getRouter().(*DefaultRouter); ok && router.HasRoutes() {
+		supervisor.Add(NewRunnableService("http router", router.Serve))
+	}
 	for _, serviceProvider := range a.moduleConfigs {
-		if appListener, ok := serviceProvider.(StartApplicationListener); ok {
-			err := appListener.OnStart()
-			if err != nil {
-				logger := a.getLogger()
-				logger.Panic(context.Background(), "Start application error: "+err.Error())
+		if servicesProvider, ok := serviceProvider.(RunnableServicesProvider); ok {
+			for _, service := range servicesProvider.RunnableServices() {
+				service.module = ModuleName(serviceProvider)
+				supervisor.Add(service)
 			}
 		}
 	}
+
+	supervisor.Serve(ctx)
+	return supervisor, nil
 }
 
-func (a *Application) onClose() {
-	for _, serviceProvider := range a.moduleConfigs {
-		if appListener, ok := serviceProvider.(CloseApplicationListener); ok {
-			err := appListener.OnClose()
+// close calls close listeners only once, so they are not repeated when Fatal is called during Run
+func (a *Application) close(ctx context.Context) error {
+	a.closeOnce.Do(func() {
+		a.closeErr = a.onClose(ctx)
+	})
+	return a.closeErr
+}
+
+// onClose calls close listeners of started modules in the reverse order until ctx is done
+func (a *Application) onClose(ctx context.Context) error {
+	a.lifecycle.Lock()
+	started := a.started
+	a.lifecycle.Unlock()
+	done := make(chan error, 1)
+	go func() {
+		var errs []error
+		for i := started - 1; i >= 0; i-- {
+			serviceProvider := a.moduleConfigs[i]
+			var err error
+			switch appListener := serviceProvider.(type) {
+			case CloseApplicationListenerWithContext:
+				err = appListener.OnClose(ctx)
+			case CloseApplicationListener:
+				err = appListener.OnClose()
+			}
 			if err != nil {
-				logger := a.getLogger()
-				logger.Panic(context.Background(), "Close application error: "+err.Error())
+				errs = append(errs, NewModuleError(serviceProvider, "close", err))
 			}
 		}
+		done <- errors.Join(errs...)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ErrShutdownTimeout
 	}
 }
 
+// setDefaults provides default implementations of services that are not provided by modules
+func (a *Application) setDefaults() {
+	a.setDefaultLogger()
+	a.setDefaultJsonResponseWriter()
+	a.setDefaultValidator()
+	a.setDefaultRouter()
+}
+
 func (a *Application) setDefaultLogger() {
 	var logger Logger
 	err := a.container.Invoke(func(dep Logger) error {
@@ -152,8 +554,9 @@ func (a *Application) setDefaultLogger() {
 		return nil
 	})
 	if err != nil || logger == nil {
-		err := a.container.Provide(NewDefaultLogger)
-		if err != nil {
+		// a provided logger that cannot be constructed stays, its config error is returned by Run
+		provideErr := a.container.Provide(NewDefaultLogger)
+		if provideErr != nil && err == nil {
 			panic("Default logger cannot be setup")
 		}
 	}
@@ -187,6 +590,21 @@ func (a *Application) setDefaultValidator() {
 	}
 }
 
+func (a *Application) setDefaultRouter() {
+	var router Router
+	err := a.container.Invoke(func(dep Router) error {
+		router = dep
+		return nil
+	})
+	if err != nil || router == nil {
+		// a provided router that cannot be constructed stays, its error is returned by Run
+		provideErr := a.container.Provide(NewDefaultRouter)
+		if provideErr != nil && err == nil {
+			panic("Default router cannot be setup")
+		}
+	}
+}
+
 func (a *Application) getLogger() Logger {
 	var logger Logger
 	err := a.container.Invoke(func(dep Logger) error {
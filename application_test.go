@@ -1,14 +1,22 @@
 package application
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/dig"
+	"os"
+	"os/exec"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestNewApplication(t *testing.T) {
 	sp := &TestSp{}
-	app := New([]ServiceProvider{sp})
+	app := New([]interface{}{sp})
 	var dp *TestDependency
 	err := app.Container().Invoke(func(dep *TestDependency) error {
 		dp = dep
@@ -20,7 +28,8 @@ func TestNewApplication(t *testing.T) {
 
 func TestRunApplication(t *testing.T) {
 	sp := &TestSp{}
-	app := New([]ServiceProvider{sp})
+	app := New([]interface{}{sp})
+	go app.Shutdown(nil)
 	err := app.Run()
 	assert.Nil(t, err)
 	var dp *TestDependency
@@ -32,6 +41,38 @@ func TestRunApplication(t *testing.T) {
 	assert.Equal(t, "test", dp.TestData)
 }
 
+func TestRunApplicationReturnsShutdownError(t *testing.T) {
+	sp := &TestSp{}
+	app := New([]interface{}{sp})
+	fatalErr := errors.New("server failed")
+	var shutdowner Shutdowner
+	err := app.Container().Invoke(func(dep Shutdowner) {
+		shutdowner = dep
+	})
+	assert.Nil(t, err)
+
+	go shutdowner.Shutdown(fatalErr)
+	err = app.Run()
+	assert.ErrorIs(t, err, fatalErr)
+}
+
+func TestRunApplicationShutdownTimeout(t *testing.T) {
+	t.Setenv("APP_SHUTDOWN_TIMEOUT", "10ms")
+	sp := &SlowCloseSp{}
+	app := New([]interface{}{sp})
+	go app.Shutdown(nil)
+	err := app.Run()
+	assert.ErrorIs(t, err, ErrShutdownTimeout)
+}
+
+type SlowCloseSp struct {
+}
+
+func (s SlowCloseSp) OnClose() error {
+	time.Sleep(time.Second)
+	return nil
+}
+
 type TestDependency struct {
 	TestData string
 }
@@ -61,3 +102,218 @@ func (t TestSp) OnClose() error {
 func (t TestSp) SetContainer(container *dig.Container) {
 	t.container = container
 }
+
+func TestRunApplicationJoinsModuleErrors(t *testing.T) {
+	startErr := errors.New("cannot start")
+	closeErr := errors.New("cannot close")
+	first := &FailingSp{startErr: startErr, closeErr: closeErr}
+	second := &FailingSp{}
+	app := New([]interface{}{first, second})
+	err := app.Run()
+
+	assert.ErrorIs(t, err, startErr)
+	assert.NotErrorIs(t, err, closeErr)
+	assert.False(t, first.closed)
+	assert.False(t, second.closed)
+	var moduleErr *ModuleError
+	assert.ErrorAs(t, err, &moduleErr)
+	assert.Equal(t, "github.com/debugger84/modulus-application", moduleErr.Module)
+}
+
+type FailingSp struct {
+	startErr error
+	closeErr error
+	closed   bool
+}
+
+func (f *FailingSp) OnStart(ctx context.Context) error {
+	return f.startErr
+}
+
+func (f *FailingSp) OnClose(ctx context.Context) error {
+	f.closed = true
+	return f.closeErr
+}
+
+func TestApplicationFatal(t *testing.T) {
+	captureLog(t)
+	sp := &FailingSp{}
+	app := New([]interface{}{sp})
+	exitCode := -1
+	app.SetExitFunc(func(code int) { exitCode = code })
+	var fataler Fataler
+	err := app.Container().Invoke(func(dep Fataler) {
+		fataler = dep
+	})
+	assert.Nil(t, err)
+
+	fatalErr := errors.New("database is gone")
+	fataler.Fatal(fatalErr)
+
+	assert.Equal(t, 1, exitCode)
+	// modules were not started, so they are not closed
+	assert.False(t, sp.closed)
+	assert.ErrorIs(t, app.Context().Err(), context.Canceled)
+
+	err = app.Run()
+	assert.ErrorIs(t, err, fatalErr)
+	assert.False(t, sp.closed)
+}
+
+func TestApplicationFatalWaitsForServices(t *testing.T) {
+	captureLog(t)
+	sp := &DrainingSp{started: make(chan struct{})}
+	app := New([]interface{}{sp})
+	exited := make(chan int, 1)
+	app.SetExitFunc(func(code int) { exited <- code })
+	var fataler Fataler
+	err := app.Container().Invoke(func(dep Fataler) {
+		fataler = dep
+	})
+	assert.Nil(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.Run() }()
+	<-sp.started
+	fatalErr := errors.New("database is gone")
+	fataler.Fatal(fatalErr)
+
+	assert.Equal(t, 1, <-exited)
+	assert.True(t, sp.drained)
+	assert.True(t, sp.closedAfterDrain)
+	assert.ErrorIs(t, <-runErr, fatalErr)
+}
+
+type DrainingSp struct {
+	started          chan struct{}
+	drained          bool
+	closedAfterDrain bool
+}
+
+func (s *DrainingSp) RunnableServices() []*RunnableService {
+	return []*RunnableService{
+		NewRunnableService("draining", func(ctx context.Context) error {
+			close(s.started)
+			<-ctx.Done()
+			time.Sleep(200 * time.Millisecond)
+			s.drained = true
+			return nil
+		}),
+	}
+}
+
+func (s *DrainingSp) OnClose(ctx context.Context) error {
+	s.closedAfterDrain = s.drained
+	return nil
+}
+
+func TestApplicationFatalOnStart(t *testing.T) {
+	captureLog(t)
+	first := &FailingSp{}
+	second := &FatalOnStartSp{err: errors.New("database is gone")}
+	third := &StartedSp{}
+	app := New([]interface{}{first, second, third})
+	exited := make(chan int, 1)
+	app.SetExitFunc(func(code int) { exited <- code })
+	err := app.Container().Invoke(func(dep Fataler) {
+		second.fataler = dep
+	})
+	assert.Nil(t, err)
+
+	err = app.Run()
+
+	assert.Equal(t, 1, <-exited)
+	assert.True(t, first.closed)
+	assert.False(t, third.started)
+	assert.ErrorIs(t, err, second.err)
+}
+
+func TestApplicationFatalFromGoroutineOnStart(t *testing.T) {
+	captureLog(t)
+	first := &FailingSp{}
+	second := &FatalOnStartSp{err: errors.New("database is gone"), inGoroutine: true}
+	third := &StartedSp{}
+	app := New([]interface{}{first, second, third})
+	exited := make(chan int, 1)
+	app.SetExitFunc(func(code int) { exited <- code })
+	err := app.Container().Invoke(func(dep Fataler) {
+		second.fataler = dep
+	})
+	assert.Nil(t, err)
+
+	err = app.Run()
+
+	assert.Equal(t, 1, <-exited)
+	assert.True(t, first.closed)
+	assert.False(t, third.started)
+	assert.ErrorIs(t, err, second.err)
+}
+
+type FatalOnStartSp struct {
+	fataler     Fataler
+	err         error
+	inGoroutine bool
+}
+
+func (s *FatalOnStartSp) OnStart(ctx context.Context) error {
+	if !s.inGoroutine {
+		s.fataler.Fatal(s.err)
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		s.fataler.Fatal(s.err)
+		close(done)
+	}()
+	<-done
+	return nil
+}
+
+type StartedSp struct {
+	started bool
+}
+
+func (s *StartedSp) OnStart() error {
+	s.started = true
+	return nil
+}
+
+func TestRunApplicationKeepsDefaultSignalsDuringBlockingStart(t *testing.T) {
+	if os.Getenv("APP_TEST_BLOCKING_START") == "1" {
+		_ = New([]interface{}{&BlockingStartSp{}}).Run()
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunApplicationKeepsDefaultSignalsDuringBlockingStart$")
+	cmd.Env = append(os.Environ(), "APP_TEST_BLOCKING_START=1")
+	stdout, err := cmd.StdoutPipe()
+	assert.Nil(t, err)
+	assert.Nil(t, cmd.Start())
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "started\n", line)
+
+	assert.Nil(t, cmd.Process.Signal(syscall.SIGINT))
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- cmd.Wait() }()
+	select {
+	case err = <-waitErr:
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatal("the process is not terminated by SIGINT")
+	}
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	status := exitErr.Sys().(syscall.WaitStatus)
+	assert.True(t, status.Signaled())
+	assert.Equal(t, syscall.SIGINT, status.Signal())
+}
+
+// BlockingStartSp blocks in OnStart like modules running a web-server there
+type BlockingStartSp struct {
+}
+
+func (s *BlockingStartSp) OnStart() error {
+	fmt.Println("started")
+	time.Sleep(time.Minute)
+	return nil
+}
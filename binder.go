@@ -0,0 +1,283 @@
+package application
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sources of request parameters in the order of increasing precedence.
+// A value of a source with a higher precedence overwrites the value of a lower one.
+// Fields marked by these tags are never filled from the request body
+const (
+	CookieSource = "cookie"
+	HeaderSource = "header"
+	QuerySource  = "query"
+	PathSource   = "path"
+)
+
+var paramSources = []string{CookieSource, HeaderSource, QuerySource, PathSource}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var errUnsupportedType = errors.New("unsupported type")
+
+// fillRequestFromParams fills fields of the request marked by path, query, header and cookie tags, for example
+//
+//	Id     int       `qs:"-" path:"id"`
+//	Page   int       `qs:"-" query:"page"`
+//	Tenant uuid.UUID `qs:"-" header:"X-Tenant"`
+//
+// Besides basic types, fields may have time.Time, time.Duration, encoding.TextUnmarshaler
+// and slice of them types. Slices are filled from repeated or comma separated values.
+func (j *ActionRunner) fillRequestFromParams(
+	w http.ResponseWriter,
+	r *http.Request,
+	request any,
+) error {
+	validationErrors := bindParams(r, j.router.RouteParams(r), request)
+	if len(validationErrors) > 0 {
+		j.jsonWriter.Error(w, r, NewValidationErrorResponse(r.Context(), validationErrors))
+		return validationErrors
+	}
+	return nil
+}
+
+func bindParams(r *http.Request, routeParams url.Values, request any) ValidationErrors {
+	v := reflect.ValueOf(request)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	var validationErrors ValidationErrors
+	bindStructParams(r, routeParams, v.Elem(), &validationErrors)
+	return validationErrors
+}
+
+func bindStructParams(r *http.Request, routeParams url.Values, v reflect.Value, validationErrors *ValidationErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindStructParams(r, routeParams, v.Field(i), validationErrors)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		for _, source := range paramSources {
+			name, ok := field.Tag.Lookup(source)
+			if !ok || name == "" {
+				continue
+			}
+			values := paramValues(r, routeParams, source, name)
+			if len(values) == 0 {
+				continue
+			}
+			if err := setFieldValue(v.Field(i), values); err != nil {
+				*validationErrors = append(*validationErrors, *NewValidationError(
+					name,
+					fmt.Sprintf("Invalid %s parameter %s: %s", source, name, conversionMessage(field.Type, err)),
+					InvalidRequest,
+				))
+			}
+		}
+	}
+}
+
+// keepParamFields clears fields marked by path, query, header or cookie tags and returns the function
+// restoring their values, so the request body cannot fill these fields but their defaults are kept.
+// The fields are cleared to prevent decoders from writing into slices, maps and pointers of the defaults
+func keepParamFields(request any) (restore func()) {
+	v := reflect.ValueOf(request)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return func() {}
+	}
+	var fields, saved []reflect.Value
+	collectParamFields(v.Elem(), &fields)
+	for _, field := range fields {
+		value := reflect.New(field.Type()).Elem()
+		value.Set(field)
+		saved = append(saved, value)
+		field.Set(reflect.Zero(field.Type()))
+	}
+	return func() {
+		for i, field := range fields {
+			field.Set(saved[i])
+		}
+	}
+}
+
+func collectParamFields(v reflect.Value, fields *[]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectParamFields(v.Field(i), fields)
+			continue
+		}
+		if field.IsExported() && hasParamTag(field) {
+			*fields = append(*fields, v.Field(i))
+		}
+	}
+}
+
+// withoutBoundParams returns values without the names qs.Unmarshal would decode into fields
+// marked by path, query, header or cookie tags, so these fields are filled only from their own sources
+func withoutBoundParams(request any, values url.Values) url.Values {
+	t := derefType(reflect.TypeOf(request))
+	if t == nil || t.Kind() != reflect.Struct {
+		return values
+	}
+	var result url.Values
+	for _, field := range structFields(t) {
+		if !hasParamTag(field) {
+			continue
+		}
+		name, _, ok := qsFieldName(field)
+		if !ok || !values.Has(name) {
+			continue
+		}
+		if result == nil {
+			result = make(url.Values, len(values))
+			for key, value := range values {
+				result[key] = value
+			}
+		}
+		delete(result, name)
+	}
+	if result == nil {
+		return values
+	}
+	return result
+}
+
+func hasParamTag(field reflect.StructField) bool {
+	for _, source := range paramSources {
+		if name, ok := field.Tag.Lookup(source); ok && name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func paramValues(r *http.Request, routeParams url.Values, source string, name string) []string {
+	switch source {
+	case PathSource:
+		return routeParams[name]
+	case QuerySource:
+		return r.URL.Query()[name]
+	case HeaderSource:
+		return r.Header.Values(name)
+	case CookieSource:
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return nil
+		}
+		return []string{cookie.Value}
+	}
+	return nil
+}
+
+func setFieldValue(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && !reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		var items []string
+		for _, value := range values {
+			items = append(items, strings.Split(value, ",")...)
+		}
+		result := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(result.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		field.Set(result)
+		return nil
+	}
+	return setValue(field, values[0])
+}
+
+func setValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err == nil {
+			field.SetInt(int64(duration))
+		}
+		return err
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return errUnsupportedType
+	}
+	return nil
+}
+
+func conversionMessage(t reflect.Type, err error) string {
+	t = derefType(t)
+	if t.Kind() == reflect.Slice && !reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		t = derefType(t.Elem())
+	}
+	switch {
+	case errors.Is(err, errUnsupportedType):
+		return "unsupported type " + t.String()
+	case t == reflect.TypeOf(time.Time{}):
+		return "should be time in RFC 3339 format"
+	case t == durationType:
+		return "should be duration"
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return err.Error()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "should be boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "should be integer"
+	case reflect.Float32, reflect.Float64:
+		return "should be number"
+	}
+	return err.Error()
+}
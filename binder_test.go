@@ -0,0 +1,175 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindTestRequest struct {
+	Id      int           `qs:"-" path:"id"`
+	Page    *int          `qs:"-" query:"page"`
+	Tags    []string      `qs:"-" query:"tag"`
+	Since   time.Time     `qs:"-" query:"since"`
+	Timeout time.Duration `qs:"-" header:"X-Timeout"`
+	Ip      net.IP        `qs:"-" header:"X-Real-Ip"`
+	Tenant  string        `qs:"-" header:"X-Tenant" cookie:"tenant"`
+	Session string        `qs:"-" cookie:"session"`
+}
+
+func TestBindParams(t *testing.T) {
+	r := httptest.NewRequest(
+		http.MethodGet,
+		"/items?page=2&tag=a,b&tag=c&since=2024-01-02T03:04:05Z",
+		nil,
+	)
+	r.Header.Set("X-Timeout", "5s")
+	r.Header.Set("X-Real-Ip", "10.0.0.1")
+	r.Header.Set("X-Tenant", "header-tenant")
+	r.AddCookie(&http.Cookie{Name: "tenant", Value: "cookie-tenant"})
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+	var request bindTestRequest
+	errs := bindParams(r, map[string][]string{"id": {"12"}}, &request)
+
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 12, request.Id)
+	assert.Equal(t, 2, *request.Page)
+	assert.Equal(t, []string{"a", "b", "c"}, request.Tags)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), request.Since)
+	assert.Equal(t, 5*time.Second, request.Timeout)
+	assert.Equal(t, "10.0.0.1", request.Ip.String())
+	assert.Equal(t, "header-tenant", request.Tenant)
+	assert.Equal(t, "abc", request.Session)
+}
+
+func TestBindParamsErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/items?page=first&since=yesterday", nil)
+
+	var request bindTestRequest
+	errs := bindParams(r, map[string][]string{"id": {"x"}}, &request)
+
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "id", errs[0].Field)
+	assert.Equal(t, "Invalid path parameter id: should be integer", errs[0].Err)
+	assert.Equal(t, "Invalid query parameter page: should be integer", errs[1].Err)
+	assert.Equal(t, "Invalid query parameter since: should be time in RFC 3339 format", errs[2].Err)
+}
+
+func TestRunBindsTaggedParams(t *testing.T) {
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Get("/items/{id}", Handle(runner, func(ctx context.Context, request *bindTestRequest) (int, error) {
+		return request.Id, nil
+	}))
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/5", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/five", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid path parameter id")
+}
+
+type bindWithoutQsTagRequest struct {
+	Id     int    `path:"id"`
+	Tenant string `header:"X-Tenant"`
+	Name   string `qs:"name"`
+}
+
+func TestRunIgnoresQueryValuesOfTaggedParams(t *testing.T) {
+	var handled bindWithoutQsTagRequest
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Get("/items/{id}", Handle(runner, func(ctx context.Context, request *bindWithoutQsTagRequest) (int, error) {
+		handled = *request
+		return request.Id, nil
+	}))
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/5?id=9&tenant=evil&name=book", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, handled.Id)
+	assert.Equal(t, "", handled.Tenant)
+	assert.Equal(t, "book", handled.Name)
+}
+
+func TestRunIgnoresBodyValuesOfTaggedParams(t *testing.T) {
+	var handled bindWithoutQsTagRequest
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Post("/items/{id}", Handle(runner, func(ctx context.Context, request *bindWithoutQsTagRequest) (int, error) {
+		handled = *request
+		return request.Id, nil
+	}))
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	r := httptest.NewRequest(
+		http.MethodPost,
+		"/items/5",
+		strings.NewReader(`{"Id":9,"Tenant":"evil","Name":"book"}`),
+	)
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, handled.Id)
+	assert.Equal(t, "", handled.Tenant)
+	assert.Equal(t, "book", handled.Name)
+}
+
+type defaultsRequest struct {
+	Page   int    `qs:"-" query:"page"`
+	Size   int    `qs:"size"`
+	Tenant string `qs:"-" header:"X-Tenant"`
+}
+
+type defaultsAction struct {
+	handled defaultsRequest
+}
+
+func (a *defaultsAction) NewRequestObject() any {
+	return &defaultsRequest{Page: 1, Size: 20, Tenant: "default"}
+}
+
+func (a *defaultsAction) Handle(ctx context.Context, request any) (any, error) {
+	a.handled = *request.(*defaultsRequest)
+	return nil, nil
+}
+
+func TestRunKeepsDefaultsOfTaggedParams(t *testing.T) {
+	action := &defaultsAction{}
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Get("/items", runner.HandleAction(action))
+	routes.Post("/items", runner.HandleAction(action))
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, defaultsRequest{Page: 1, Size: 20, Tenant: "default"}, action.handled)
+
+	r := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"Page":5,"Size":10,"Tenant":"evil"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, defaultsRequest{Page: 1, Size: 10, Tenant: "default"}, action.handled)
+}
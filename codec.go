@@ -0,0 +1,233 @@
+package application
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/pasztorpisti/qs"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	JsonMediaType    = "application/json"
+	XmlMediaType     = "application/xml"
+	FormMediaType    = "application/x-www-form-urlencoded"
+	MsgpackMediaType = "application/msgpack"
+
+	MultipartMediaType = "multipart/form-data"
+)
+
+// Codec decodes request bodies and encodes responses of one media type
+type Codec interface {
+	MediaType() string
+	Decode(r io.Reader, v any) error
+	Encode(w io.Writer, v any) error
+}
+
+// CodecRegistry holds codecs by media types. Modules can take it from the container
+// and register own codecs until the application is started
+type CodecRegistry struct {
+	mu           sync.RWMutex
+	codecs       map[string]Codec
+	order        []string
+	defaultCodec Codec
+}
+
+// NewCodecRegistry creates a registry with JSON, XML, form and MessagePack codecs. JSON is the default one
+func NewCodecRegistry() *CodecRegistry {
+	registry := &CodecRegistry{codecs: make(map[string]Codec)}
+	registry.Register(JsonCodec{})
+	registry.Register(XmlCodec{})
+	registry.Register(FormCodec{})
+	registry.Register(MsgpackCodec{})
+	registry.defaultCodec = JsonCodec{}
+	return registry
+}
+
+// Register adds the codec or replaces a codec of the same media type
+func (c *CodecRegistry) Register(codec Codec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	mediaType := strings.ToLower(codec.MediaType())
+	if _, exists := c.codecs[mediaType]; !exists {
+		c.order = append(c.order, mediaType)
+	}
+	c.codecs[mediaType] = codec
+}
+
+// Decoder returns the codec for the Content-Type header value, parameters like charset are ignored
+func (c *CodecRegistry) Decoder(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	codec, ok := c.codecs[mediaType]
+	return codec, ok
+}
+
+// Negotiate returns the codec for the most preferred media type of the Accept header value.
+// An empty header accepts the default codec. Media types with a structured syntax suffix,
+// like application/problem+json, accept the codec of the suffix
+func (c *CodecRegistry) Negotiate(accept string) (Codec, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if strings.TrimSpace(accept) == "" {
+		return c.defaultCodec, true
+	}
+	for _, mediaRange := range parseAccept(accept) {
+		if mediaRange == "*/*" {
+			return c.defaultCodec, true
+		}
+		if codec, ok := c.codecs[mediaRange]; ok {
+			return codec, true
+		}
+		if codec, ok := c.suffixCodec(mediaRange); ok {
+			return codec, true
+		}
+		if prefix, ok := strings.CutSuffix(mediaRange, "/*"); ok {
+			if strings.HasPrefix(c.defaultCodec.MediaType(), prefix+"/") {
+				return c.defaultCodec, true
+			}
+			for _, mediaType := range c.order {
+				if strings.HasPrefix(mediaType, prefix+"/") {
+					return c.codecs[mediaType], true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// suffixCodec returns the codec of the structured syntax suffix of the media type,
+// for example the JSON codec for application/problem+json
+func (c *CodecRegistry) suffixCodec(mediaType string) (Codec, bool) {
+	index := strings.LastIndex(mediaType, "+")
+	if index < 0 {
+		return nil, false
+	}
+	codec, ok := c.codecs["application/"+mediaType[index+1:]]
+	return codec, ok
+}
+
+// parseAccept returns media ranges of the Accept header ordered by their quality
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		value   string
+		quality float64
+	}
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{value: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	result := make([]string, len(ranges))
+	for i, r := range ranges {
+		result[i] = r.value
+	}
+	return result
+}
+
+type JsonCodec struct {
+}
+
+func (c JsonCodec) MediaType() string {
+	return JsonMediaType
+}
+
+func (c JsonCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func (c JsonCodec) Encode(w io.Writer, v any) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+type XmlCodec struct {
+}
+
+func (c XmlCodec) MediaType() string {
+	return XmlMediaType
+}
+
+func (c XmlCodec) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+func (c XmlCodec) Encode(w io.Writer, v any) error {
+	content, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// FormCodec decodes and encodes url encoded forms by qs tags
+type FormCodec struct {
+}
+
+func (c FormCodec) MediaType() string {
+	return FormMediaType
+}
+
+func (c FormCodec) Decode(r io.Reader, v any) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return qs.Unmarshal(v, string(content))
+}
+
+func (c FormCodec) Encode(w io.Writer, v any) error {
+	content, err := qs.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+type MsgpackCodec struct {
+}
+
+func (c MsgpackCodec) MediaType() string {
+	return MsgpackMediaType
+}
+
+func (c MsgpackCodec) Decode(r io.Reader, v any) error {
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(v)
+}
+
+func (c MsgpackCodec) Encode(w io.Writer, v any) error {
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetCustomStructTag("json")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,123 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCodecRegistryNegotiate(t *testing.T) {
+	registry := NewCodecRegistry()
+	cases := map[string]string{
+		"":                                      JsonMediaType,
+		"*/*":                                   JsonMediaType,
+		"application/xml":                       XmlMediaType,
+		"text/html;q=0.9, application/msgpack":  MsgpackMediaType,
+		"application/xml;q=0.5, application/*":  JsonMediaType,
+		"text/html, application/xml;q=0.1, */*": JsonMediaType,
+		"application/problem+json":              JsonMediaType,
+	}
+	for accept, expected := range cases {
+		codec, ok := registry.Negotiate(accept)
+		assert.True(t, ok, accept)
+		assert.Equal(t, expected, codec.MediaType(), accept)
+	}
+
+	_, ok := registry.Negotiate("text/html, application/xml;q=0")
+	assert.False(t, ok)
+}
+
+func TestCodecRegistryDecoderIgnoresParameters(t *testing.T) {
+	registry := NewCodecRegistry()
+	codec, ok := registry.Decoder("application/json; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, JsonMediaType, codec.MediaType())
+
+	_, ok = registry.Decoder("text/csv")
+	assert.False(t, ok)
+}
+
+func newCodecTestRouter() *DefaultRouter {
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Post("/users", Handle(
+		runner,
+		func(ctx context.Context, request *handleTestResponse) (*handleTestResponse, error) {
+			return request, nil
+		},
+	))
+	router.AddRoutes(routes.GetRoutesInfo())
+	return router
+}
+
+func TestRunNegotiatesContent(t *testing.T) {
+	router := newCodecTestRouter()
+
+	body, err := msgpack.Marshal(map[string]any{"id": 1, "name": "John"})
+	assert.Nil(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/msgpack")
+	r.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, XmlMediaType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "<handleTestResponse><Id>1</Id><Name>John</Name></handleTestResponse>", w.Body.String())
+
+	r = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"id":2}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":2,"name":""}`, w.Body.String())
+}
+
+func TestRunRejectsUnsupportedContent(t *testing.T) {
+	router := newCodecTestRouter()
+
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`id,name`))
+	r.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, JsonMediaType, w.Header().Get("Content-Type"))
+}
+
+func TestSuccessFallsBackToJsonOnEncodingError(t *testing.T) {
+	writer := NewJsonResponseWriter(NewDefaultLogger(), NewConfig(), NewCodecRegistry())
+
+	r := httptest.NewRequest(http.MethodGet, "/users", nil)
+	r.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	writer.Success(w, r, NewSuccessResponse(map[string]int{"id": 1}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, JsonMediaType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id":1}`, w.Body.String())
+}
+
+func TestSuccessWritesServerErrorIfResponseCannotBeEncoded(t *testing.T) {
+	writer := NewJsonResponseWriter(NewDefaultLogger(), NewConfig(), NewCodecRegistry())
+
+	r := httptest.NewRequest(http.MethodGet, "/users", nil)
+	w := httptest.NewRecorder()
+	writer.Success(w, r, NewSuccessResponse(make(chan int)))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error":"Response cannot be encoded"}`, w.Body.String())
+}
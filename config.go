@@ -1,10 +1,15 @@
 package application
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"go.uber.org/dig"
-	"os"
+	"log"
+	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ServiceProvider describes all services of a module in the dependency injection container
@@ -28,6 +33,13 @@ type ConfigInitializer interface {
 	InitConfig(config Config) error
 }
 
+// ConfigInitializerWithContext is the same as ConfigInitializer,
+// but receives the root context of the application
+type ConfigInitializerWithContext interface {
+	// InitConfig is called for each module to initialize module's variables
+	InitConfig(ctx context.Context, config Config) error
+}
+
 // HttpRoutesInitializer if service provider implements this method it will be called after
 // initializing the configuration and its result will be added to the http routes
 // listened by the application router
@@ -43,6 +55,13 @@ type StartApplicationListener interface {
 	OnStart() error
 }
 
+// StartApplicationListenerWithContext is the same as StartApplicationListener,
+// but receives the root context of the application that is cancelled on the application stopping
+type StartApplicationListenerWithContext interface {
+	// OnStart Starts module's application such as a web-server
+	OnStart(ctx context.Context) error
+}
+
 // CloseApplicationListener if service provider implements this method it will be called after
 // stopping the application
 type CloseApplicationListener interface {
@@ -50,8 +69,20 @@ type CloseApplicationListener interface {
 	OnClose() error
 }
 
+// CloseApplicationListenerWithContext is the same as CloseApplicationListener,
+// but receives a context that is cancelled when the shutdown timeout is exceeded
+type CloseApplicationListenerWithContext interface {
+	// OnClose may close some resources of a module, for example a db connection
+	OnClose(ctx context.Context) error
+}
+
+// Config reads values of keys from its sources, the first source having the value wins
 type Config struct {
-	appEnv string
+	appEnv          string
+	sources         []ConfigSource
+	secretProviders []SecretProvider
+	schema          map[string]ConfigKey
+	readErrors      *configReadErrors
 }
 
 const (
@@ -60,22 +91,46 @@ const (
 	ProdEnv = "prod"
 
 	defaultEnv = TestEnv
+
+	defaultShutdownTimeout = 30 * time.Second
+	defaultHttpAddr        = ":8080"
 )
 
-func NewConfig() *Config {
-	return &Config{}
+// ErrConfigKeyNotFound is returned by Parse... getters of the config if the key is not set
+var ErrConfigKeyNotFound = errors.New("config key is not found")
+
+// NewConfig creates a config reading the sources in the order of decreasing precedence.
+// Without sources the config reads the process environment
+func NewConfig(sources ...ConfigSource) *Config {
+	if len(sources) == 0 {
+		sources = []ConfigSource{NewEnvSource()}
+	}
+	return &Config{sources: sources, readErrors: &configReadErrors{}}
+}
+
+// AddSource adds the source with the highest precedence
+func (c *Config) AddSource(source ConfigSource) {
+	c.sources = append([]ConfigSource{source}, c.sources...)
+	c.appEnv = ""
 }
 
 func (c *Config) ProvidedServices() []interface{} {
 	return []interface{}{
 		NewActionRunner,
+		NewSupervisor,
+		NewGlobalMiddlewares,
+		NewCodecRegistry,
+		NewErrorRegistry,
+		NewRecoverer,
 		func() *Config { return c },
 	}
 }
 
+// AppEnv returns the environment of the application read from the APP_ENV variable.
+// The test environment is used if the variable is not set
 func (c *Config) AppEnv() string {
 	if c.appEnv == "" {
-		c.appEnv = c.GetEnv("APP_ENV")
+		c.appEnv = c.GetEnvOrDefault("APP_ENV", defaultEnv)
 	}
 	return c.appEnv
 }
@@ -84,36 +139,221 @@ func (c *Config) AppEnvIsProd() bool {
 	return c.AppEnv() == ProdEnv
 }
 
-func (c *Config) GetEnv(key string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// ShutdownTimeout returns the time given to close listeners to release resources.
+// It is read from the optional APP_SHUTDOWN_TIMEOUT variable, for example "10s"
+func (c *Config) ShutdownTimeout() time.Duration {
+	return c.GetEnvAsDurationOrDefault("APP_SHUTDOWN_TIMEOUT", defaultShutdownTimeout)
+}
+
+// HttpAddr returns the address listened by the default router.
+// It is read from the optional HTTP_ADDR variable
+func (c *Config) HttpAddr() string {
+	return c.GetEnvOrDefault("HTTP_ADDR", defaultHttpAddr)
+}
+
+// ProblemTypeBaseUri returns the base of problem type URIs written by ProblemDetailsResponseWriter.
+// It is read from the optional PROBLEM_TYPE_BASE_URI variable, for example "https://example.com/problems"
+func (c *Config) ProblemTypeBaseUri() string {
+	return c.GetEnvOrDefault("PROBLEM_TYPE_BASE_URI", "")
+}
+
+// ExposeStackTraces returns true if stack traces of recovered panics may be written to responses.
+// It is read from the optional APP_EXPOSE_STACK_TRACES variable and is ignored in the prod environment.
+// ConfigKeyError is returned if the value is not boolean
+func (c *Config) ExposeStackTraces() (bool, error) {
+	expose, err := c.ParseEnvAsBool("APP_EXPOSE_STACK_TRACES")
+	if errors.Is(err, ErrConfigKeyNotFound) {
+		return false, nil
+	}
+	return expose, err
+}
+
+// LogLevel returns the level of the slog logger read from the optional LOG_LEVEL variable:
+// debug, info (default), warn or error. ConfigKeyError is returned for other values
+func (c *Config) LogLevel() (slog.Level, error) {
+	level := slog.LevelInfo
+	if value, exists := c.LookupEnv("LOG_LEVEL"); exists {
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			return level, ConfigKeyError{Key: "LOG_LEVEL", Err: "should be one of debug, info, warn, error"}
+		}
 	}
+	return level, nil
+}
 
-	panic("The key " + key + " is not exists in the .env file")
+// LogFormat returns the format of the slog logger read from the optional LOG_FORMAT variable: text (default) or json
+func (c *Config) LogFormat() string {
+	return strings.ToLower(c.GetEnvOrDefault("LOG_FORMAT", "text"))
 }
 
-func (c *Config) GetEnvAsInt(name string) int {
-	valueStr := c.GetEnv(name)
-	if value, err := strconv.Atoi(valueStr); err == nil {
-		return value
+// LookupEnv returns the value of the key and false if the key is not set or its secret cannot be read.
+// The read error is kept by the config and returned by ReadErrors, so the application does not start
+func (c *Config) LookupEnv(key string) (string, bool) {
+	value, exists, err := c.ResolveEnv(key)
+	if err != nil {
+		return "", false
 	}
+	return value, exists
+}
 
-	panic("The value of the key " + name + " in the .env file should be Integer")
+// ResolveEnv returns the value of the key, false if the key is not set
+// and the error if the secret of the key cannot be read
+func (c *Config) ResolveEnv(key string) (string, bool, error) {
+	value, err := c.resolve(key)
+	if err != nil {
+		return "", false, err
+	}
+	return value.value, value.exists, nil
 }
 
-func (c *Config) GetEnvAsBool(name string) bool {
-	valStr := c.GetEnv(name)
-	if val, err := strconv.ParseBool(valStr); err == nil {
-		return val
+// lookup returns the raw value of the key from the source with the highest precedence
+func (c *Config) lookup(key string) (string, bool) {
+	for _, source := range c.sources {
+		if value, exists := source.Lookup(key); exists {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// ParseEnv returns the value of the key or ErrConfigKeyNotFound if the key is not set
+func (c *Config) ParseEnv(key string) (string, error) {
+	value, err := c.resolve(key)
+	if err != nil {
+		return "", err
+	}
+	if !value.exists {
+		return "", fmt.Errorf("%w: %s", ErrConfigKeyNotFound, key)
+	}
+	return value.value, nil
+}
+
+// ParseEnvAsInt returns ErrConfigKeyNotFound if the key is not set and ConfigKeyError if the value is not integer
+func (c *Config) ParseEnvAsInt(name string) (int, error) {
+	valueStr, err := c.ParseEnv(name)
+	if err != nil {
+		return 0, err
 	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return 0, ConfigKeyError{Key: name, Err: "should be integer"}
+	}
+	return value, nil
+}
+
+// ParseEnvAsBool returns ErrConfigKeyNotFound if the key is not set and ConfigKeyError if the value is not boolean
+func (c *Config) ParseEnvAsBool(name string) (bool, error) {
+	valueStr, err := c.ParseEnv(name)
+	if err != nil {
+		return false, err
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return false, ConfigKeyError{Key: name, Err: "should be boolean"}
+	}
+	return value, nil
+}
+
+// ParseEnvAsDuration returns ErrConfigKeyNotFound if the key is not set and ConfigKeyError if the value is not duration
+func (c *Config) ParseEnvAsDuration(name string) (time.Duration, error) {
+	valueStr, err := c.ParseEnv(name)
+	if err != nil {
+		return 0, err
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		return 0, ConfigKeyError{Key: name, Err: "should be duration"}
+	}
+	return value, nil
+}
+
+// ParseEnvAsSlice returns ErrConfigKeyNotFound if the key is not set
+func (c *Config) ParseEnvAsSlice(name string, sep string) ([]string, error) {
+	valueStr, err := c.ParseEnv(name)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(valueStr, sep), nil
+}
+
+func (c *Config) GetEnv(key string) string {
+	value, err := c.ParseEnv(key)
+	panicOnConfigError(key, err, "")
+	return value
+}
+
+func (c *Config) GetEnvAsInt(name string) int {
+	value, err := c.ParseEnvAsInt(name)
+	panicOnConfigError(name, err, "Integer")
+	return value
+}
 
-	panic("The value of the key " + name + " in the .env file should be Boolean")
+func (c *Config) GetEnvAsBool(name string) bool {
+	value, err := c.ParseEnvAsBool(name)
+	panicOnConfigError(name, err, "Boolean")
+	return value
 }
 
 func (c *Config) GetEnvAsSlice(name string, sep string) []string {
-	valStr := c.GetEnv(name)
+	value, err := c.ParseEnvAsSlice(name, sep)
+	panicOnConfigError(name, err, "")
+	return value
+}
+
+// GetEnvOrDefault returns the default value if the key is not set.
+// Like all ...OrDefault getters it never panics, see orDefault
+func (c *Config) GetEnvOrDefault(key string, defaultValue string) string {
+	value, err := c.ParseEnv(key)
+	return orDefault(value, err, defaultValue)
+}
+
+// GetEnvAsIntOrDefault returns the default value if the key is not set or the value is not integer
+func (c *Config) GetEnvAsIntOrDefault(name string, defaultValue int) int {
+	value, err := c.ParseEnvAsInt(name)
+	return orDefault(value, err, defaultValue)
+}
 
-	val := strings.Split(valStr, sep)
+// GetEnvAsBoolOrDefault returns the default value if the key is not set or the value is not boolean
+func (c *Config) GetEnvAsBoolOrDefault(name string, defaultValue bool) bool {
+	value, err := c.ParseEnvAsBool(name)
+	return orDefault(value, err, defaultValue)
+}
 
-	return val
+// GetEnvAsDurationOrDefault returns the default value if the key is not set or the value is not duration
+func (c *Config) GetEnvAsDurationOrDefault(name string, defaultValue time.Duration) time.Duration {
+	value, err := c.ParseEnvAsDuration(name)
+	return orDefault(value, err, defaultValue)
+}
+
+// GetEnvAsSliceOrDefault returns the default value if the key is not set
+func (c *Config) GetEnvAsSliceOrDefault(name string, sep string, defaultValue []string) []string {
+	value, err := c.ParseEnvAsSlice(name, sep)
+	return orDefault(value, err, defaultValue)
+}
+
+// orDefault is the policy of the ...OrDefault getters: they never panic and return the default value
+// if the key is not set, its value is malformed or its secret cannot be read. The last two cases are logged.
+// Use ParseEnv... variants to handle such errors, keys declared by ConfigSchema are validated by Run
+func orDefault[T any](value T, err error, defaultValue T) T {
+	if err == nil {
+		return value
+	}
+	if !errors.Is(err, ErrConfigKeyNotFound) {
+		log.Println("WARN:", err.Error()+", the default value is used")
+	}
+	return defaultValue
+}
+
+// panicOnConfigError keeps panic messages of the Get... getters
+func panicOnConfigError(name string, err error, typeName string) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, ErrConfigKeyNotFound) {
+		panic("The key " + name + " is not exists in the .env file")
+	}
+	var keyErr ConfigKeyError
+	if !errors.As(err, &keyErr) {
+		panic(err.Error())
+	}
+	panic("The value of the key " + name + " in the .env file should be " + typeName)
 }
@@ -0,0 +1,173 @@
+package application
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const defaultConfigSeparator = ","
+const configMapKeySeparator = ":"
+
+var urlType = reflect.TypeOf(url.URL{})
+
+// ConfigKeyError describes a missing or malformed key of the config
+type ConfigKeyError struct {
+	Key string
+	Err string
+}
+
+func (e ConfigKeyError) Error() string {
+	return e.Key + ": " + e.Err
+}
+
+// ConfigErrors allows Bind to report all missing and malformed keys as one error
+type ConfigErrors []ConfigKeyError
+
+func (e ConfigErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, keyError := range e {
+		messages[i] = keyError.Error()
+	}
+	return "invalid config: " + strings.Join(messages, "; ")
+}
+
+// Bind fills fields of the struct pointed by target from the variables named by env tags, for example
+//
+//	type BillingConfig struct {
+//		ApiUrl  *url.URL           `env:"BILLING_API_URL" required:"true"`
+//		Timeout time.Duration      `env:"BILLING_TIMEOUT" default:"5s"`
+//		Hosts   []string           `env:"BILLING_HOSTS" separator:";"`
+//		Rates   map[string]float64 `env:"BILLING_RATES" default:"usd:1,eur:0.92"`
+//		Db      DbConfig           `prefix:"BILLING_"`
+//	}
+//
+// Besides basic types, fields may have time.Duration, time.Time, url.URL, encoding.TextUnmarshaler, slice and map types.
+// Slices and maps are split by the separator tag or by comma, keys of maps are separated from values by colon.
+// Fields of nested structs are bound with names prefixed by the prefix tag of the struct field.
+// All missing and malformed keys are returned together as ConfigErrors.
+func (c *Config) Bind(target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config target should be a pointer to a struct, %T given", target)
+	}
+	var configErrors ConfigErrors
+	c.bindStruct(v.Elem(), "", &configErrors)
+	if len(configErrors) > 0 {
+		return configErrors
+	}
+	return nil
+}
+
+func (c *Config) bindStruct(v reflect.Value, prefix string, configErrors *ConfigErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, ok := field.Tag.Lookup("env")
+		if !ok || name == "" {
+			if isNestedConfig(field.Type) {
+				c.bindStruct(v.Field(i), prefix+field.Tag.Get("prefix"), configErrors)
+			}
+			continue
+		}
+		key := prefix + name
+		resolved, err := c.resolve(key)
+		if err != nil {
+			*configErrors = append(*configErrors, ConfigKeyError{Key: key, Err: err.Error()})
+			continue
+		}
+		value, exists := resolved.value, resolved.exists
+		if !exists || value == "" {
+			if defaultValue, hasDefault := field.Tag.Lookup("default"); hasDefault {
+				value, exists = defaultValue, true
+			}
+		}
+		if !exists || value == "" {
+			if field.Tag.Get("required") == "true" {
+				*configErrors = append(*configErrors, ConfigKeyError{Key: key, Err: "is required"})
+			}
+			continue
+		}
+		separator := field.Tag.Get("separator")
+		if separator == "" {
+			separator = defaultConfigSeparator
+		}
+		if err := setConfigValue(v.Field(i), value, separator); err != nil {
+			*configErrors = append(*configErrors, ConfigKeyError{Key: key, Err: configConversionMessage(field.Type, err)})
+		}
+	}
+}
+
+// isNestedConfig returns true for struct fields whose own fields should be bound
+func isNestedConfig(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct &&
+		t != urlType &&
+		!reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func setConfigValue(field reflect.Value, value string, separator string) error {
+	switch {
+	case field.Type() == urlType:
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(*parsed))
+		return nil
+	case field.Kind() == reflect.Ptr:
+		ptr := reflect.New(field.Type().Elem())
+		if err := setConfigValue(ptr.Elem(), value, separator); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	case field.Kind() == reflect.Map:
+		result := reflect.MakeMap(field.Type())
+		for _, item := range strings.Split(value, separator) {
+			itemKey, itemValue, found := strings.Cut(item, configMapKeySeparator)
+			if !found {
+				return fmt.Errorf("map item %q should be in the key%svalue format", item, configMapKeySeparator)
+			}
+			mapKey := reflect.New(field.Type().Key()).Elem()
+			if err := setConfigValue(mapKey, strings.TrimSpace(itemKey), separator); err != nil {
+				return fmt.Errorf("map key %q is invalid", itemKey)
+			}
+			mapValue := reflect.New(field.Type().Elem()).Elem()
+			if err := setConfigValue(mapValue, strings.TrimSpace(itemValue), separator); err != nil {
+				return err
+			}
+			result.SetMapIndex(mapKey, mapValue)
+		}
+		field.Set(result)
+		return nil
+	case field.Kind() == reflect.Slice && !reflect.PointerTo(field.Type()).Implements(textUnmarshalerType):
+		items := strings.Split(value, separator)
+		result := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setConfigValue(result.Index(i), strings.TrimSpace(item), separator); err != nil {
+				return err
+			}
+		}
+		field.Set(result)
+		return nil
+	}
+	return setValue(field, value)
+}
+
+func configConversionMessage(t reflect.Type, err error) string {
+	t = derefType(t)
+	switch {
+	case t == urlType:
+		return "should be URL: " + err.Error()
+	case t.Kind() == reflect.Map:
+		if strings.HasPrefix(err.Error(), "map ") {
+			return err.Error()
+		}
+		return "map value " + conversionMessage(t.Elem(), err)
+	}
+	return conversionMessage(t, err)
+}
@@ -0,0 +1,81 @@
+package application
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type bindDbConfig struct {
+	Host string `env:"DB_HOST" default:"localhost"`
+	Port int    `env:"DB_PORT" default:"5432"`
+}
+
+type bindBillingConfig struct {
+	ApiUrl   *url.URL           `env:"BILLING_API_URL" required:"true"`
+	Callback url.URL            `env:"BILLING_CALLBACK_URL"`
+	Timeout  time.Duration      `env:"BILLING_TIMEOUT" default:"5s"`
+	Fee      float64            `env:"BILLING_FEE"`
+	Since    time.Time          `env:"BILLING_SINCE"`
+	Hosts    []string           `env:"BILLING_HOSTS" separator:";"`
+	Ports    []int              `env:"BILLING_PORTS"`
+	Rates    map[string]float64 `env:"BILLING_RATES" default:"usd:1,eur:0.92"`
+	Enabled  *bool              `env:"BILLING_ENABLED"`
+	Db       bindDbConfig       `prefix:"BILLING_"`
+	ignored  string             `env:"BILLING_IGNORED"`
+}
+
+func TestConfigBind(t *testing.T) {
+	t.Setenv("BILLING_API_URL", "https://billing.example.com/api")
+	t.Setenv("BILLING_CALLBACK_URL", "https://example.com/callback")
+	t.Setenv("BILLING_FEE", "0.25")
+	t.Setenv("BILLING_SINCE", "2024-01-02T03:04:05Z")
+	t.Setenv("BILLING_HOSTS", "a.example.com; b.example.com")
+	t.Setenv("BILLING_PORTS", "80,443")
+	t.Setenv("BILLING_ENABLED", "true")
+	t.Setenv("BILLING_DB_HOST", "db")
+	t.Setenv("BILLING_IGNORED", "value")
+
+	var cfg bindBillingConfig
+	err := NewConfig().Bind(&cfg)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "billing.example.com", cfg.ApiUrl.Host)
+	assert.Equal(t, "/callback", cfg.Callback.Path)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, 0.25, cfg.Fee)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Since)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, cfg.Hosts)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, map[string]float64{"usd": 1, "eur": 0.92}, cfg.Rates)
+	assert.True(t, *cfg.Enabled)
+	assert.Equal(t, bindDbConfig{Host: "db", Port: 5432}, cfg.Db)
+	assert.Empty(t, cfg.ignored)
+}
+
+func TestConfigBindReportsAllErrors(t *testing.T) {
+	t.Setenv("BILLING_API_URL", "")
+	t.Setenv("BILLING_TIMEOUT", "soon")
+	t.Setenv("BILLING_PORTS", "80,http")
+	t.Setenv("BILLING_RATES", "usd=1")
+	t.Setenv("BILLING_DB_PORT", "main")
+
+	var cfg bindBillingConfig
+	err := NewConfig().Bind(&cfg)
+
+	var configErrors ConfigErrors
+	assert.ErrorAs(t, err, &configErrors)
+	assert.Equal(t, ConfigErrors{
+		{Key: "BILLING_API_URL", Err: "is required"},
+		{Key: "BILLING_TIMEOUT", Err: "should be duration"},
+		{Key: "BILLING_PORTS", Err: "should be integer"},
+		{Key: "BILLING_RATES", Err: `map item "usd=1" should be in the key:value format`},
+		{Key: "BILLING_DB_PORT", Err: "should be integer"},
+	}, configErrors)
+}
+
+func TestConfigBindWrongTarget(t *testing.T) {
+	var cfg bindBillingConfig
+	assert.Error(t, NewConfig().Bind(cfg))
+}
@@ -0,0 +1,229 @@
+package application
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type ConfigKeyType string
+
+const (
+	ConfigTypeString   ConfigKeyType = "string"
+	ConfigTypeInt      ConfigKeyType = "int"
+	ConfigTypeBool     ConfigKeyType = "bool"
+	ConfigTypeFloat    ConfigKeyType = "float"
+	ConfigTypeDuration ConfigKeyType = "duration"
+	ConfigTypeUrl      ConfigKeyType = "url"
+)
+
+// ConfigKey describes a key of the config used by a module
+type ConfigKey struct {
+	Name string
+	// Type is checked by the application on start, the string type is used by default
+	Type ConfigKeyType
+	// Default is returned by the config if the key is not set
+	Default     string
+	Description string
+	Required    bool
+	// Secret values are redacted in the config report
+	Secret bool
+}
+
+// ConfigSchemaProvider if module config implements this method the application checks the declared keys
+// before the config initialization and before OnStart of any module
+type ConfigSchemaProvider interface {
+	// ConfigSchema returns keys of the config used by the module
+	ConfigSchema() []ConfigKey
+}
+
+// ConfigSchema returns keys of the config used by the application itself
+func (c *Config) ConfigSchema() []ConfigKey {
+	return []ConfigKey{
+		{Name: "APP_ENV", Default: defaultEnv, Description: "test (default), dev, prod"},
+		{
+			Name:        "APP_SHUTDOWN_TIMEOUT",
+			Type:        ConfigTypeDuration,
+			Default:     defaultShutdownTimeout.String(),
+			Description: "time given to modules to close their resources",
+		},
+		{Name: "HTTP_ADDR", Default: defaultHttpAddr, Description: "address listened by the default router"},
+		{
+			Name:        "PROBLEM_TYPE_BASE_URI",
+			Type:        ConfigTypeUrl,
+			Description: "base of problem type URIs in application/problem+json responses, for example https://example.com/problems",
+		},
+		{
+			Name:        "APP_EXPOSE_STACK_TRACES",
+			Type:        ConfigTypeBool,
+			Default:     "false",
+			Description: "write stack traces of recovered panics to responses, ignored in the prod environment",
+		},
+		{Name: "LOG_LEVEL", Default: "info", Description: "level of the slog logger: debug, info, warn, error"},
+		{Name: "LOG_FORMAT", Default: "text", Description: "format of the slog logger: text, json"},
+		{
+			Name:        "APP_CONFIG_FILE",
+			Description: "path of a YAML or JSON config file read with a higher precedence than config.$APP_ENV.yaml and config.yaml",
+		},
+		{Name: "APP_SECRETS_DIR", Description: "directory with secrets mounted as files, for example /run/secrets"},
+		{
+			Name:        "APP_PRINT_CONFIG",
+			Type:        ConfigTypeBool,
+			Default:     "false",
+			Description: "log the redacted effective config on start",
+		},
+	}
+}
+
+// DeclareKeys makes the config return defaults of the keys and redact their secret values.
+// A key declared earlier is not overwritten
+func (c *Config) DeclareKeys(keys ...ConfigKey) {
+	if c.schema == nil {
+		c.schema = make(map[string]ConfigKey)
+	}
+	for _, key := range keys {
+		if _, exists := c.schema[key.Name]; !exists {
+			c.schema[key.Name] = key
+		}
+	}
+}
+
+// ValidateKeys returns ConfigErrors with all missing required keys and values not matching declared types
+func (c *Config) ValidateKeys(keys []ConfigKey) error {
+	var configErrors ConfigErrors
+	for _, key := range keys {
+		if !isKnownConfigType(key.Type) {
+			configErrors = append(configErrors, ConfigKeyError{Key: key.Name, Err: "has unknown type " + string(key.Type)})
+			continue
+		}
+		value, err := c.resolve(key.Name)
+		if err != nil {
+			configErrors = append(configErrors, ConfigKeyError{Key: key.Name, Err: err.Error()})
+			continue
+		}
+		if !value.exists || value.value == "" {
+			if key.Required {
+				configErrors = append(configErrors, ConfigKeyError{Key: key.Name, Err: "is required"})
+			}
+			continue
+		}
+		if message := checkConfigType(key.Type, value.value); message != "" {
+			configErrors = append(configErrors, ConfigKeyError{Key: key.Name, Err: message})
+		}
+	}
+	if len(configErrors) > 0 {
+		return configErrors
+	}
+	return nil
+}
+
+// WriteConfigReport writes values of the keys, secret values are redacted
+func (c *Config) WriteConfigReport(w io.Writer, keys []ConfigKey) error {
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key.Name, c.RedactedValue(key.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteEnvDist writes the .env.dist file documenting the keys. Optional keys are commented out
+func WriteEnvDist(w io.Writer, keys []ConfigKey) error {
+	for _, key := range keys {
+		var line string
+		if key.Description != "" {
+			line = "# " + key.Description + "\n"
+		}
+		if !key.Required {
+			line += "#"
+		}
+		value := key.Default
+		if key.Secret {
+			value = ""
+		}
+		line += key.Name + "=" + value + "\n"
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnvDistDiff describes differences between a .env.dist file and declared keys
+type EnvDistDiff struct {
+	// Missing keys are declared, but not documented in the file
+	Missing []string
+	// Unknown keys are documented in the file, but not declared
+	Unknown []string
+}
+
+func (d EnvDistDiff) IsEmpty() bool {
+	return len(d.Missing) == 0 && len(d.Unknown) == 0
+}
+
+var envDistKeyRegexp = regexp.MustCompile(`^#?([A-Za-z_][A-Za-z0-9_]*)=`)
+
+// DiffEnvDist compares keys of the .env.dist file with the declared keys.
+// Both set (KEY=) and commented out (#KEY=) keys of the file are taken into account,
+// comments like "# for example KEY=value" are not
+func DiffEnvDist(r io.Reader, keys []ConfigKey) (EnvDistDiff, error) {
+	documented := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if match := envDistKeyRegexp.FindStringSubmatch(scanner.Text()); match != nil {
+			documented[match[1]] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return EnvDistDiff{}, err
+	}
+
+	var diff EnvDistDiff
+	declared := make(map[string]bool)
+	for _, key := range keys {
+		declared[key.Name] = true
+		if !documented[key.Name] {
+			diff.Missing = append(diff.Missing, key.Name)
+		}
+	}
+	for name := range documented {
+		if !declared[name] {
+			diff.Unknown = append(diff.Unknown, name)
+		}
+	}
+	sort.Strings(diff.Unknown)
+	return diff, nil
+}
+
+func isKnownConfigType(keyType ConfigKeyType) bool {
+	switch keyType {
+	case "", ConfigTypeString, ConfigTypeInt, ConfigTypeBool, ConfigTypeFloat, ConfigTypeDuration, ConfigTypeUrl:
+		return true
+	}
+	return false
+}
+
+func checkConfigType(keyType ConfigKeyType, value string) string {
+	var err error
+	switch keyType {
+	case ConfigTypeInt:
+		_, err = strconv.Atoi(value)
+	case ConfigTypeBool:
+		_, err = strconv.ParseBool(value)
+	case ConfigTypeFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case ConfigTypeDuration:
+		_, err = time.ParseDuration(value)
+	case ConfigTypeUrl:
+		_, err = url.ParseRequestURI(value)
+	}
+	if err != nil {
+		return "should be " + string(keyType)
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package application
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+var billingSchema = []ConfigKey{
+	{Name: "BILLING_API_URL", Type: ConfigTypeUrl, Required: true, Description: "URL of the billing API"},
+	{Name: "BILLING_TIMEOUT", Type: ConfigTypeDuration, Default: "5s", Description: "timeout of API calls"},
+	{Name: "BILLING_RETRIES", Type: ConfigTypeInt, Default: "3"},
+	{Name: "BILLING_TOKEN", Required: true, Secret: true, Description: "API token"},
+}
+
+type SchemaSp struct {
+	started bool
+}
+
+func (s *SchemaSp) ConfigSchema() []ConfigKey {
+	return billingSchema
+}
+
+func (s *SchemaSp) OnStart() error {
+	s.started = true
+	return nil
+}
+
+func TestConfigValidateKeys(t *testing.T) {
+	config := NewConfig(MapSource{"BILLING_API_URL": "billing", "BILLING_RETRIES": "many"})
+	config.DeclareKeys(billingSchema...)
+
+	err := config.ValidateKeys(billingSchema)
+
+	assert.Equal(t, ConfigErrors{
+		{Key: "BILLING_API_URL", Err: "should be url"},
+		{Key: "BILLING_RETRIES", Err: "should be int"},
+		{Key: "BILLING_TOKEN", Err: "is required"},
+	}, err)
+}
+
+func TestConfigValidateKeysRejectsUnknownTypes(t *testing.T) {
+	config := NewConfig(MapSource{"BILLING_RATE": "1.5"})
+
+	err := config.ValidateKeys([]ConfigKey{
+		{Name: "BILLING_RATE", Type: "decimal"},
+		{Name: "BILLING_CURRENCY", Type: "currency"},
+		{Name: "BILLING_NAME"},
+	})
+
+	assert.Equal(t, ConfigErrors{
+		{Key: "BILLING_RATE", Err: "has unknown type decimal"},
+		{Key: "BILLING_CURRENCY", Err: "has unknown type currency"},
+	}, err)
+}
+
+func TestConfigDeclaredKeys(t *testing.T) {
+	config := NewConfig(MapSource{"BILLING_API_URL": "https://billing.example.com", "BILLING_TOKEN": "token"})
+	config.DeclareKeys(billingSchema...)
+
+	assert.NoError(t, config.ValidateKeys(billingSchema))
+	assert.Equal(t, "5s", config.GetEnv("BILLING_TIMEOUT"))
+	assert.True(t, config.IsSecret("BILLING_TOKEN"))
+
+	report := &bytes.Buffer{}
+	assert.Nil(t, config.WriteConfigReport(report, billingSchema))
+	assert.Equal(t, "BILLING_API_URL=https://billing.example.com\n"+
+		"BILLING_TIMEOUT=5s\n"+
+		"BILLING_RETRIES=3\n"+
+		"BILLING_TOKEN=******\n", report.String())
+}
+
+func TestWriteEnvDist(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, WriteEnvDist(buf, billingSchema))
+
+	assert.Equal(t, "# URL of the billing API\n"+
+		"BILLING_API_URL=\n"+
+		"# timeout of API calls\n"+
+		"#BILLING_TIMEOUT=5s\n"+
+		"#BILLING_RETRIES=3\n"+
+		"# API token\n"+
+		"BILLING_TOKEN=\n", buf.String())
+}
+
+func TestDiffEnvDist(t *testing.T) {
+	file := "# URL of the billing API\nBILLING_API_URL=\n#BILLING_TIMEOUT=5s\nBILLING_OLD_KEY=1\n" +
+		"# retries, for example BILLING_RETRIES=3\n#  BILLING_TOKEN=\n"
+
+	diff, err := DiffEnvDist(strings.NewReader(file), billingSchema)
+
+	assert.Nil(t, err)
+	assert.Equal(t, EnvDistDiff{
+		Missing: []string{"BILLING_RETRIES", "BILLING_TOKEN"},
+		Unknown: []string{"BILLING_OLD_KEY"},
+	}, diff)
+	assert.False(t, diff.IsEmpty())
+}
+
+func TestEnvDistMatchesApplicationSchema(t *testing.T) {
+	file, err := os.Open(".env.dist")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	diff, err := DiffEnvDist(file, NewConfig().ConfigSchema())
+
+	assert.Nil(t, err)
+	assert.True(t, diff.IsEmpty(), "%+v", diff)
+}
+
+func TestRunApplicationValidatesConfigBeforeStart(t *testing.T) {
+	t.Setenv("BILLING_RETRIES", "many")
+	sp := &SchemaSp{}
+	app := New([]interface{}{sp})
+
+	err := app.Run()
+
+	var moduleErr *ModuleError
+	assert.True(t, errors.As(err, &moduleErr))
+	assert.Equal(t, "config validation", moduleErr.Stage)
+	var configErrors ConfigErrors
+	assert.True(t, errors.As(err, &configErrors))
+	assert.Len(t, configErrors, 3)
+	assert.False(t, sp.started)
+}
+
+func TestApplicationConfigReport(t *testing.T) {
+	t.Setenv("BILLING_API_URL", "https://billing.example.com")
+	t.Setenv("BILLING_TOKEN", "token")
+	app := New([]interface{}{&SchemaSp{}})
+
+	report := &bytes.Buffer{}
+	assert.Nil(t, app.WriteConfigReport(report))
+
+	assert.Contains(t, report.String(), "BILLING_TOKEN=******\n")
+	assert.Contains(t, report.String(), "BILLING_TIMEOUT=5s\n")
+	assert.Contains(t, report.String(), "HTTP_ADDR=:8080\n")
+	assert.Len(t, app.ConfigSchema(), len(billingSchema)+len(NewConfig().ConfigSchema()))
+}
@@ -0,0 +1,197 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FileKeySuffix marks a key whose value is read from the file, for example DB_PASSWORD_FILE=/run/secrets/db_password
+const FileKeySuffix = "_FILE"
+
+const redactedValue = "******"
+
+// Secret is a string that is redacted when it is printed, logged or marshalled.
+// Fields of this type are filled by Config.Bind the same way as strings
+type Secret string
+
+// Value returns the real value of the secret
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	return redactedValue
+}
+
+func (s Secret) GoString() string {
+	return redactedValue
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redactedValue)
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redactedValue), nil
+}
+
+// SecretProvider provides values of keys that should be redacted, for example from a vault.
+// Secret providers are asked for keys that are not found in sources of the config
+type SecretProvider interface {
+	LookupSecret(key string) (string, bool, error)
+}
+
+// SecretsDirSource reads secrets mounted as files of a directory, for example by Kubernetes.
+// The file name is the key itself or the key in lower case, so DB_PASSWORD is read from db_password
+type SecretsDirSource struct {
+	dir string
+}
+
+func NewSecretsDirSource(dir string) *SecretsDirSource {
+	return &SecretsDirSource{dir: dir}
+}
+
+func (s *SecretsDirSource) LookupSecret(key string) (string, bool, error) {
+	for _, name := range []string{key, strings.ToLower(key)} {
+		value, err := readSecretFile(filepath.Join(s.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return value, true, nil
+	}
+	return "", false, nil
+}
+
+// AddSecretProvider adds the provider asked for keys after previously added providers
+func (c *Config) AddSecretProvider(provider SecretProvider) {
+	c.secretProviders = append(c.secretProviders, provider)
+}
+
+// IsSecret returns true if the key is declared as secret or its value is read from a file
+// by the _FILE suffixed key or from a secret provider
+func (c *Config) IsSecret(key string) bool {
+	value, err := c.resolve(key)
+	return err == nil && value.secret
+}
+
+// RedactedValue returns the value of the key or a placeholder if the value is secret
+func (c *Config) RedactedValue(key string) string {
+	value, err := c.resolve(key)
+	if err != nil || !value.exists {
+		return ""
+	}
+	if value.secret {
+		return redactedValue
+	}
+	return value.value
+}
+
+// GetSecret returns the value of the key as Secret and panics if it is not set
+func (c *Config) GetSecret(key string) Secret {
+	return Secret(c.GetEnv(key))
+}
+
+// ReadErrors returns errors of all secrets that could not be read by the config so far.
+// Run returns them after InitConfig of all modules
+func (c *Config) ReadErrors() error {
+	if c.readErrors == nil {
+		return nil
+	}
+	return c.readErrors.err()
+}
+
+// configReadErrors is shared by copies of the config passed to InitConfig
+type configReadErrors struct {
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+func (e *configReadErrors) add(key string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.errs == nil {
+		e.errs = make(map[string]error)
+	}
+	e.errs[key] = err
+}
+
+func (e *configReadErrors) err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	keys := make([]string, 0, len(e.errs))
+	for key := range e.errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	errs := make([]error, 0, len(keys))
+	for _, key := range keys {
+		errs = append(errs, e.errs[key])
+	}
+	return errors.Join(errs...)
+}
+
+type configValue struct {
+	value  string
+	exists bool
+	secret bool
+}
+
+// resolve reads the key from sources of the config, then from the file named by the _FILE suffixed key,
+// then from secret providers, then takes the declared default
+func (c *Config) resolve(key string) (configValue, error) {
+	value, err := c.resolveValue(key)
+	if err != nil && c.readErrors != nil {
+		c.readErrors.add(key, err)
+	}
+	if declared, ok := c.schema[key]; ok {
+		if !value.exists && declared.Default != "" {
+			value = configValue{value: declared.Default, exists: true}
+		}
+		value.secret = value.secret || declared.Secret
+	}
+	return value, err
+}
+
+func (c *Config) resolveValue(key string) (configValue, error) {
+	for _, source := range c.sources {
+		if value, exists := source.Lookup(key); exists {
+			return configValue{value: value, exists: true}, nil
+		}
+	}
+	if !strings.HasSuffix(key, FileKeySuffix) {
+		if filename, exists := c.lookup(key + FileKeySuffix); exists && filename != "" {
+			value, err := readSecretFile(filename)
+			if err != nil {
+				return configValue{}, fmt.Errorf("cannot read the secret file of the key %s: %w", key, err)
+			}
+			return configValue{value: value, exists: true, secret: true}, nil
+		}
+	}
+	for _, provider := range c.secretProviders {
+		value, exists, err := provider.LookupSecret(key)
+		if err != nil {
+			return configValue{}, fmt.Errorf("cannot read the secret %s: %w", key, err)
+		}
+		if exists {
+			return configValue{value: value, exists: true, secret: true}, nil
+		}
+	}
+	return configValue{}, nil
+}
+
+func readSecretFile(filename string) (string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
@@ -0,0 +1,116 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mapSecretProvider is a stand-in of a vault
+type mapSecretProvider map[string]string
+
+func (p mapSecretProvider) LookupSecret(key string) (string, bool, error) {
+	value, ok := p[key]
+	return value, ok, nil
+}
+
+func TestConfigFileSuffixedKey(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "db_password")
+	assert.Nil(t, os.WriteFile(filename, []byte("s3cret\n"), 0o600))
+	config := NewConfig(MapSource{
+		"DB_PASSWORD_FILE":   filename,
+		"DB_USER":            "app",
+		"BROKEN_SECRET_FILE": filepath.Join(dir, "missing"),
+	})
+
+	assert.Equal(t, "s3cret", config.GetEnv("DB_PASSWORD"))
+	assert.True(t, config.IsSecret("DB_PASSWORD"))
+	assert.False(t, config.IsSecret("DB_USER"))
+	assert.Equal(t, "******", config.RedactedValue("DB_PASSWORD"))
+	assert.Equal(t, "app", config.RedactedValue("DB_USER"))
+
+	_, err := config.ParseEnv("BROKEN_SECRET")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Panics(t, func() { config.GetEnv("BROKEN_SECRET") })
+}
+
+func TestConfigReportsUnreadableSecrets(t *testing.T) {
+	buf := captureLog(t)
+	config := NewConfig(MapSource{"DB_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing")})
+
+	_, _, err := config.ResolveEnv("DB_PASSWORD")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Equal(t, "default", config.GetEnvOrDefault("DB_PASSWORD", "default"))
+	assert.Contains(t, buf.String(), "cannot read the secret file of the key DB_PASSWORD")
+	assert.ErrorIs(t, config.ReadErrors(), os.ErrNotExist)
+	assert.Nil(t, NewConfig(MapSource{}).ReadErrors())
+}
+
+func TestRunApplicationReturnsUnreadableSecrets(t *testing.T) {
+	captureLog(t)
+	t.Setenv("DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	app := New([]interface{}{&OptionalSecretSp{}})
+	go app.Shutdown(nil)
+
+	err := app.Run()
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Contains(t, err.Error(), "DB_PASSWORD")
+}
+
+func TestConfigSecretProviders(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "api_token"), []byte("token-from-dir"), 0o600))
+	config := NewConfig(MapSource{"API_TOKEN_FILE": "", "DB_USER": "app"})
+	config.AddSecretProvider(NewSecretsDirSource(dir))
+	config.AddSecretProvider(mapSecretProvider{"API_TOKEN": "token-from-vault", "VAULT_KEY": "key"})
+
+	assert.Equal(t, "token-from-dir", config.GetEnv("API_TOKEN"))
+	assert.Equal(t, "key", config.GetEnv("VAULT_KEY"))
+	assert.True(t, config.IsSecret("VAULT_KEY"))
+	_, exists := config.LookupEnv("MISSING")
+	assert.False(t, exists)
+}
+
+func TestConfigBindSecret(t *testing.T) {
+	config := NewConfig(MapSource{})
+	config.AddSecretProvider(mapSecretProvider{"BILLING_TOKEN": "token"})
+	var cfg struct {
+		Token Secret `env:"BILLING_TOKEN" required:"true"`
+	}
+
+	assert.Nil(t, config.Bind(&cfg))
+	assert.Equal(t, "token", cfg.Token.Value())
+}
+
+func TestSecretIsRedacted(t *testing.T) {
+	secret := Secret("token")
+
+	assert.Equal(t, "****** ******", fmt.Sprintf("%s %v", secret, secret))
+	encoded, err := json.Marshal(map[string]Secret{"token": secret})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"token": "******"}`, string(encoded))
+
+	buf := &bytes.Buffer{}
+	NewSlogLoggerWithHandler(NewSlogHandler(buf, "json", slog.LevelInfo)).
+		Info(context.Background(), "connected", slog.Any("token", secret))
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "******", line["token"])
+}
+
+type OptionalSecretSp struct {
+	password string
+}
+
+func (s *OptionalSecretSp) InitConfig(config Config) error {
+	s.password = config.GetEnvOrDefault("DB_PASSWORD", "")
+	return nil
+}
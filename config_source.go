@@ -0,0 +1,161 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigSource provides raw values of config keys. Keys are named the same way as environment variables,
+// for example BILLING_API_URL
+type ConfigSource interface {
+	Lookup(key string) (string, bool)
+}
+
+// EnvSource reads the process environment including variables loaded from .env files by the application
+type EnvSource struct{}
+
+func NewEnvSource() *EnvSource {
+	return &EnvSource{}
+}
+
+func (s *EnvSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// MapSource holds config values in memory, for example in tests
+type MapSource map[string]string
+
+func (s MapSource) Lookup(key string) (string, bool) {
+	value, ok := s[key]
+	return value, ok
+}
+
+// NewFlagSource reads command-line arguments like --billing-api-url=https://example.com.
+// Names of flags are converted to keys by upper-casing them and replacing dashes and dots with underscores.
+// Other arguments are ignored. Flags are not read by default, an application reading them adds the source
+//
+//	app.Config().AddSource(application.NewFlagSource(os.Args[1:]))
+func NewFlagSource(args []string) MapSource {
+	source := MapSource{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		name, value, found := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !found || name == "" {
+			continue
+		}
+		source[configKey(name)] = value
+	}
+	return source
+}
+
+// NewFileSource reads a YAML (.yaml, .yml) or JSON (.json) file. Nested keys are joined by underscores
+// and upper-cased, so the file
+//
+//	billing:
+//	  api_url: https://example.com
+//	  hosts: [a.example.com, b.example.com]
+//
+// provides BILLING_API_URL and BILLING_HOSTS keys. Lists are joined by commas
+func NewFileSource(filename string) (MapSource, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the config file %s: %w", filename, err)
+	}
+	var values map[string]any
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	case ".json":
+		err = json.Unmarshal(content, &values)
+	default:
+		return nil, fmt.Errorf("unsupported format of the config file %s", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the config file %s: %w", filename, err)
+	}
+	source := MapSource{}
+	flattenConfigValues(source, "", values)
+	return source, nil
+}
+
+// defaultConfigSources returns sources of the application config in the order of decreasing precedence:
+//   - the process environment including variables of .env.$APP_ENV and .env files
+//   - the file from the APP_CONFIG_FILE variable
+//   - config.$APP_ENV.yaml, config.$APP_ENV.yml or config.$APP_ENV.json of the dir
+//   - config.yaml, config.yml or config.json of the dir
+func defaultConfigSources(dir string) ([]ConfigSource, error) {
+	sources := []ConfigSource{NewEnvSource()}
+	envConfig := NewConfig(sources...)
+
+	if filename, exists := envConfig.LookupEnv("APP_CONFIG_FILE"); exists {
+		source, err := NewFileSource(filename)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	for _, name := range []string{"config." + envConfig.AppEnv(), "config"} {
+		for _, ext := range []string{".yaml", ".yml", ".json"} {
+			source, err := NewFileSource(filepath.Join(dir, name+ext))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+func flattenConfigValues(source MapSource, prefix string, values map[string]any) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := configKey(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		switch value := values[key].(type) {
+		case map[string]any:
+			flattenConfigValues(source, name, value)
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = configValueString(item)
+			}
+			source[name] = strings.Join(items, ",")
+		default:
+			source[name] = configValueString(value)
+		}
+	}
+}
+
+func configValueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func configKey(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
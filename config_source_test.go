@@ -0,0 +1,131 @@
+package application
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewFileSourceYaml(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(filename, []byte(`
+billing:
+  api-url: https://billing.example.com
+  timeout: 5s
+  retries: 3
+  fee: 0.25
+  enabled: true
+  hosts: [a.example.com, b.example.com]
+`), 0o600)
+	assert.Nil(t, err)
+
+	source, err := NewFileSource(filename)
+
+	assert.Nil(t, err)
+	assert.Equal(t, MapSource{
+		"BILLING_API_URL": "https://billing.example.com",
+		"BILLING_TIMEOUT": "5s",
+		"BILLING_RETRIES": "3",
+		"BILLING_FEE":     "0.25",
+		"BILLING_ENABLED": "true",
+		"BILLING_HOSTS":   "a.example.com,b.example.com",
+	}, source)
+}
+
+func TestNewFileSourceJson(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(filename, []byte(`{"billing": {"retries": 3, "fee": 0.25, "empty": null}}`), 0o600)
+	assert.Nil(t, err)
+
+	source, err := NewFileSource(filename)
+
+	assert.Nil(t, err)
+	assert.Equal(t, MapSource{"BILLING_RETRIES": "3", "BILLING_FEE": "0.25", "BILLING_EMPTY": ""}, source)
+}
+
+func TestNewFileSourceMalformed(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "broken.json")
+	err := os.WriteFile(filename, []byte(`{"billing": `), 0o600)
+	assert.Nil(t, err)
+
+	_, err = NewFileSource(filename)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot parse the config file")
+}
+
+func TestNewFileSourceMissing(t *testing.T) {
+	_, err := NewFileSource(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestNewFlagSource(t *testing.T) {
+	source := NewFlagSource([]string{"serve", "--billing-api-url=https://example.com", "--LOG_LEVEL=debug", "-v", "--verbose"})
+
+	assert.Equal(t, MapSource{"BILLING_API_URL": "https://example.com", "LOG_LEVEL": "debug"}, source)
+}
+
+func TestConfigSourcesPrecedence(t *testing.T) {
+	config := NewConfig(
+		MapSource{"BILLING_FEE": "0.5"},
+		MapSource{"BILLING_FEE": "0.25", "BILLING_RETRIES": "3"},
+	)
+	assert.Equal(t, "0.5", config.GetEnv("BILLING_FEE"))
+	assert.Equal(t, 3, config.GetEnvAsInt("BILLING_RETRIES"))
+
+	config.AddSource(NewFlagSource([]string{"--billing-retries=5"}))
+	assert.Equal(t, 5, config.GetEnvAsInt("BILLING_RETRIES"))
+}
+
+func TestDefaultConfigSources(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(
+		filepath.Join(dir, "config.yaml"),
+		[]byte("billing:\n  fee: 0.1\n  retries: 1\n  timeout: 1s\n  url: https://common\n"),
+		0o600,
+	)
+	assert.Nil(t, err)
+	err = os.WriteFile(
+		filepath.Join(dir, "config.dev.json"),
+		[]byte(`{"billing": {"fee": 0.2, "retries": 2, "timeout": "2s"}}`),
+		0o600,
+	)
+	assert.Nil(t, err)
+	explicit := filepath.Join(dir, "billing.yaml")
+	err = os.WriteFile(explicit, []byte("billing:\n  fee: 0.3\n  retries: 3\n"), 0o600)
+	assert.Nil(t, err)
+	t.Setenv("APP_ENV", "dev")
+	t.Setenv("APP_CONFIG_FILE", explicit)
+	t.Setenv("BILLING_FEE", "0.4")
+
+	sources, err := defaultConfigSources(dir)
+	assert.Nil(t, err)
+	config := NewConfig(sources...)
+
+	assert.Equal(t, "0.4", config.GetEnv("BILLING_FEE"))
+	assert.Equal(t, 3, config.GetEnvAsInt("BILLING_RETRIES"))
+	assert.Equal(t, "2s", config.GetEnv("BILLING_TIMEOUT"))
+	assert.Equal(t, "https://common", config.GetEnv("BILLING_URL"))
+}
+
+func TestDefaultConfigSourcesMissingConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("APP_CONFIG_FILE", filepath.Join(dir, "missing.yaml"))
+
+	_, err := defaultConfigSources(dir)
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestNewApplicationIgnoresFlags(t *testing.T) {
+	args := os.Args
+	os.Args = []string{"app", "--billing-retries=5"}
+	defer func() { os.Args = args }()
+
+	app := New([]interface{}{})
+
+	_, exists := app.Config().LookupEnv("BILLING_RETRIES")
+	assert.False(t, exists)
+}
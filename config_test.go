@@ -0,0 +1,78 @@
+package application
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestConfigAppEnvDefault(t *testing.T) {
+	assert.Equal(t, TestEnv, NewConfig(MapSource{}).AppEnv())
+}
+
+func TestConfigParseEnv(t *testing.T) {
+	t.Setenv("CONFIG_TEST_INT", "5")
+	t.Setenv("CONFIG_TEST_WRONG_INT", "five")
+	config := NewConfig()
+
+	value, ok := config.LookupEnv("CONFIG_TEST_INT")
+	assert.True(t, ok)
+	assert.Equal(t, "5", value)
+	_, ok = config.LookupEnv("CONFIG_TEST_MISSING")
+	assert.False(t, ok)
+
+	intValue, err := config.ParseEnvAsInt("CONFIG_TEST_INT")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, intValue)
+
+	_, err = config.ParseEnvAsInt("CONFIG_TEST_MISSING")
+	assert.ErrorIs(t, err, ErrConfigKeyNotFound)
+
+	_, err = config.ParseEnvAsInt("CONFIG_TEST_WRONG_INT")
+	assert.Equal(t, ConfigKeyError{Key: "CONFIG_TEST_WRONG_INT", Err: "should be integer"}, err)
+
+	_, err = config.ParseEnvAsBool("CONFIG_TEST_WRONG_INT")
+	assert.EqualError(t, err, "CONFIG_TEST_WRONG_INT: should be boolean")
+}
+
+func TestConfigGetEnvOrDefault(t *testing.T) {
+	t.Setenv("CONFIG_TEST_BOOL", "true")
+	t.Setenv("CONFIG_TEST_DURATION", "wrong")
+	config := NewConfig()
+
+	assert.Equal(t, "default", config.GetEnvOrDefault("CONFIG_TEST_MISSING", "default"))
+	assert.Equal(t, 7, config.GetEnvAsIntOrDefault("CONFIG_TEST_MISSING", 7))
+	assert.True(t, config.GetEnvAsBoolOrDefault("CONFIG_TEST_BOOL", false))
+	assert.Equal(t, time.Second, config.GetEnvAsDurationOrDefault("CONFIG_TEST_MISSING", time.Second))
+	assert.Equal(t, []string{"a"}, config.GetEnvAsSliceOrDefault("CONFIG_TEST_MISSING", ",", []string{"a"}))
+}
+
+func TestConfigGetEnvOrDefaultLogsMalformedValues(t *testing.T) {
+	buf := captureLog(t)
+	config := NewConfig(MapSource{
+		"CONFIG_TEST_INT":      "five",
+		"CONFIG_TEST_BOOL":     "sometimes",
+		"CONFIG_TEST_DURATION": "wrong",
+	})
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, 7, config.GetEnvAsIntOrDefault("CONFIG_TEST_INT", 7))
+		assert.True(t, config.GetEnvAsBoolOrDefault("CONFIG_TEST_BOOL", true))
+		assert.Equal(t, time.Second, config.GetEnvAsDurationOrDefault("CONFIG_TEST_DURATION", time.Second))
+	})
+	assert.Equal(t, "WARN: CONFIG_TEST_INT: should be integer, the default value is used\n"+
+		"WARN: CONFIG_TEST_BOOL: should be boolean, the default value is used\n"+
+		"WARN: CONFIG_TEST_DURATION: should be duration, the default value is used\n", buf.String())
+}
+
+func TestConfigGetEnvPanics(t *testing.T) {
+	t.Setenv("CONFIG_TEST_WRONG_INT", "five")
+	config := NewConfig()
+
+	assert.PanicsWithValue(t, "The key CONFIG_TEST_MISSING is not exists in the .env file", func() {
+		config.GetEnvAsInt("CONFIG_TEST_MISSING")
+	})
+	assert.PanicsWithValue(t, "The value of the key CONFIG_TEST_WRONG_INT in the .env file should be Integer", func() {
+		config.GetEnvAsInt("CONFIG_TEST_WRONG_INT")
+	})
+}
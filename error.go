@@ -1,6 +1,12 @@
 package application
 
-import "context"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
 
 type ErrorIdentifier string
 
@@ -8,10 +14,57 @@ const WrongRequestDecoding ErrorIdentifier = "WrongRequestDecoding"
 const InvalidRequest ErrorIdentifier = "InvalidRequest"
 const UnprocessableEntity ErrorIdentifier = "UnprocessableEntity"
 const UnknownError ErrorIdentifier = "UnknownError"
+const RequestTooLarge ErrorIdentifier = "RequestTooLarge"
+const UnsupportedMediaType ErrorIdentifier = "UnsupportedMediaType"
+const NotAcceptable ErrorIdentifier = "NotAcceptable"
+const Unauthorized ErrorIdentifier = "Unauthorized"
+const Forbidden ErrorIdentifier = "Forbidden"
+const NotFound ErrorIdentifier = "NotFound"
+const MethodNotAllowed ErrorIdentifier = "MethodNotAllowed"
+const Conflict ErrorIdentifier = "Conflict"
+const Gone ErrorIdentifier = "Gone"
+const PreconditionFailed ErrorIdentifier = "PreconditionFailed"
+const TooManyRequests ErrorIdentifier = "TooManyRequests"
+const NotImplemented ErrorIdentifier = "NotImplemented"
+const ServiceUnavailable ErrorIdentifier = "ServiceUnavailable"
+const Timeout ErrorIdentifier = "Timeout"
+
+// errorStatusCodes is the catalogue of standard error identifiers and their response status codes
+var errorStatusCodes = map[ErrorIdentifier]int{
+	WrongRequestDecoding: http.StatusInternalServerError,
+	InvalidRequest:       http.StatusBadRequest,
+	UnprocessableEntity:  http.StatusUnprocessableEntity,
+	UnknownError:         http.StatusInternalServerError,
+	RequestTooLarge:      http.StatusRequestEntityTooLarge,
+	UnsupportedMediaType: http.StatusUnsupportedMediaType,
+	NotAcceptable:        http.StatusNotAcceptable,
+	Unauthorized:         http.StatusUnauthorized,
+	Forbidden:            http.StatusForbidden,
+	NotFound:             http.StatusNotFound,
+	MethodNotAllowed:     http.StatusMethodNotAllowed,
+	Conflict:             http.StatusConflict,
+	Gone:                 http.StatusGone,
+	PreconditionFailed:   http.StatusPreconditionFailed,
+	TooManyRequests:      http.StatusTooManyRequests,
+	NotImplemented:       http.StatusNotImplemented,
+	ServiceUnavailable:   http.StatusServiceUnavailable,
+	Timeout:              http.StatusGatewayTimeout,
+}
+
+// StatusCode returns the response status code of a standard error identifier
+// and false if the identifier is not in the catalogue
+func StatusCode(identifier ErrorIdentifier) (int, bool) {
+	code, ok := errorStatusCodes[identifier]
+	return code, ok
+}
 
 type CommonError struct {
 	Identifier ErrorIdentifier
 	Err        string
+	// Details are copied to the ActionError of the response
+	Details map[string]any
+	// RetryAfter is copied to the ActionError of the response
+	RetryAfter time.Duration
 }
 
 func (e *CommonError) Error() string {
@@ -30,18 +83,57 @@ type ActionError struct {
 	Identifier       ErrorIdentifier
 	Err              error
 	ValidationErrors []ValidationError
+	// Details holds machine-readable data describing the error, for example a conflicting entity id
+	Details map[string]any
+	// RetryAfter hints a client when the request may be repeated
+	RetryAfter time.Duration
 }
 
 func (e *ActionError) Error() string {
 	return e.Err.Error()
 }
 
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails returns a copy of the error response with the details attached to its error
+func (r ActionResponse) WithDetails(details map[string]any) ActionResponse {
+	if r.Error != nil {
+		actionError := *r.Error
+		actionError.Details = details
+		r.Error = &actionError
+	}
+	return r
+}
+
+// WithRetryAfter returns a copy of the error response with the retry hint attached to its error
+func (r ActionResponse) WithRetryAfter(retryAfter time.Duration) ActionResponse {
+	if r.Error != nil {
+		actionError := *r.Error
+		actionError.RetryAfter = retryAfter
+		r.Error = &actionError
+	}
+	return r
+}
+
 type ValidationError struct {
 	Field      string
 	Identifier ErrorIdentifier
 	Err        string
 }
 
+// ValidationErrors allows an action to return several validation errors as one error
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, validationError := range e {
+		messages[i] = validationError.Err
+	}
+	return strings.Join(messages, "; ")
+}
+
 func NewValidationError(field string, err string, identifier ErrorIdentifier) *ValidationError {
 	return &ValidationError{Field: field, Err: err, Identifier: identifier}
 }
@@ -62,12 +154,138 @@ func NewServerErrorResponse(ctx context.Context, identifier ErrorIdentifier, err
 	}
 }
 
+func NewRequestTooLargeResponse(ctx context.Context, err error) ActionResponse {
+	return ActionResponse{
+		StatusCode: 413,
+		Error: &ActionError{
+			Ctx:              ctx,
+			Identifier:       RequestTooLarge,
+			Err:              err,
+			ValidationErrors: nil,
+		},
+	}
+}
+
+func NewUnsupportedMediaTypeResponse(ctx context.Context, contentType string) ActionResponse {
+	return ActionResponse{
+		StatusCode: 415,
+		Error: &ActionError{
+			Ctx:              ctx,
+			Identifier:       UnsupportedMediaType,
+			Err:              errors.New("Unsupported content type " + contentType),
+			ValidationErrors: nil,
+		},
+	}
+}
+
+func NewNotAcceptableResponse(ctx context.Context, accept string) ActionResponse {
+	return ActionResponse{
+		StatusCode: 406,
+		Error: &ActionError{
+			Ctx:              ctx,
+			Identifier:       NotAcceptable,
+			Err:              errors.New("None of accepted content types is supported: " + accept),
+			ValidationErrors: nil,
+		},
+	}
+}
+
+// NewActionErrorResponse creates an error response with the status code of the identifier from the catalogue.
+// Identifiers missing in the catalogue get the 500 status code
+func NewActionErrorResponse(ctx context.Context, identifier ErrorIdentifier, err error) ActionResponse {
+	code, ok := StatusCode(identifier)
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+	return ActionResponse{
+		StatusCode: code,
+		Error: &ActionError{
+			Ctx:              ctx,
+			Identifier:       identifier,
+			Err:              err,
+			ValidationErrors: nil,
+		},
+	}
+}
+
+func NewUnauthorizedResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, Unauthorized, err)
+}
+
+func NewForbiddenResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, Forbidden, err)
+}
+
+func NewNotFoundResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, NotFound, err)
+}
+
+func NewMethodNotAllowedResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, MethodNotAllowed, err)
+}
+
+func NewConflictResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, Conflict, err)
+}
+
+func NewGoneResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, Gone, err)
+}
+
+func NewPreconditionFailedResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, PreconditionFailed, err)
+}
+
+func NewTooManyRequestsResponse(ctx context.Context, err error, retryAfter time.Duration) ActionResponse {
+	return NewActionErrorResponse(ctx, TooManyRequests, err).WithRetryAfter(retryAfter)
+}
+
+func NewNotImplementedResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, NotImplemented, err)
+}
+
+func NewServiceUnavailableResponse(ctx context.Context, err error, retryAfter time.Duration) ActionResponse {
+	return NewActionErrorResponse(ctx, ServiceUnavailable, err).WithRetryAfter(retryAfter)
+}
+
+func NewTimeoutResponse(ctx context.Context, err error) ActionResponse {
+	return NewActionErrorResponse(ctx, Timeout, err)
+}
+
+// legacyUnprocessableIdentifiers keep the 422 status code in CommonError responses
+// as they had before the catalogue was added
+var legacyUnprocessableIdentifiers = map[ErrorIdentifier]bool{
+	WrongRequestDecoding: true,
+	InvalidRequest:       true,
+	UnprocessableEntity:  true,
+	UnknownError:         true,
+}
+
+// NewUnprocessableEntityResponse creates the 422 response for errors wrapping *CommonError.
+// If the identifier of the CommonError is in the catalogue, its status code is used,
+// except the original identifiers like UnknownError that keep the 422 status code.
+// Other errors get the 500 status code
 func NewUnprocessableEntityResponse(ctx context.Context, err error) ActionResponse {
 	code := 500
 	identifier := UnprocessableEntity
-	if commonErr, ok := err.(*CommonError); ok {
+	var commonErr *CommonError
+	if errors.As(err, &commonErr) {
 		code = 422
 		identifier = commonErr.Identifier
+		if catalogueCode, ok := StatusCode(identifier); ok && !legacyUnprocessableIdentifiers[identifier] {
+			code = catalogueCode
+		}
+		return ActionResponse{
+			StatusCode: code,
+			Error: &ActionError{
+				Ctx:              ctx,
+				Identifier:       identifier,
+				Err:              err,
+				ValidationErrors: nil,
+				Details:          commonErr.Details,
+				RetryAfter:       commonErr.RetryAfter,
+			},
+		}
 	}
 	return ActionResponse{
 		StatusCode: code,
@@ -96,3 +314,21 @@ func NewValidationErrorResponse(ctx context.Context, errors []ValidationError) A
 		},
 	}
 }
+
+// NewErrorResponse converts an error returned by an action to the response.
+// Validation errors become 400 responses, other errors are processed by NewUnprocessableEntityResponse
+func NewErrorResponse(ctx context.Context, err error) ActionResponse {
+	var validationErrors ValidationErrors
+	if errors.As(err, &validationErrors) && len(validationErrors) > 0 {
+		return NewValidationErrorResponse(ctx, validationErrors)
+	}
+	var validationError ValidationError
+	if errors.As(err, &validationError) {
+		return NewValidationErrorResponse(ctx, []ValidationError{validationError})
+	}
+	var validationErrorPtr *ValidationError
+	if errors.As(err, &validationErrorPtr) && validationErrorPtr != nil {
+		return NewValidationErrorResponse(ctx, []ValidationError{*validationErrorPtr})
+	}
+	return NewUnprocessableEntityResponse(ctx, err)
+}
@@ -0,0 +1,74 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrorRegistry maps errors returned by actions to response status codes and identifiers.
+// Errors are matched through wrapped error chains, so an action can just return a wrapped error.
+// Modules can take the registry from the container and register their errors until the application is started
+type ErrorRegistry struct {
+	mu       sync.RWMutex
+	mappings []errorMapping
+}
+
+type errorMapping struct {
+	matches    func(err error) bool
+	statusCode int
+	identifier ErrorIdentifier
+}
+
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{}
+}
+
+// Register maps the sentinel error matched by errors.Is to the status code and the identifier
+func (e *ErrorRegistry) Register(target error, statusCode int, identifier ErrorIdentifier) {
+	e.add(errorMapping{
+		matches: func(err error) bool {
+			return errors.Is(err, target)
+		},
+		statusCode: statusCode,
+		identifier: identifier,
+	})
+}
+
+// RegisterErrorType maps errors of the type T matched by errors.As to the status code and the identifier
+func RegisterErrorType[T error](registry *ErrorRegistry, statusCode int, identifier ErrorIdentifier) {
+	registry.add(errorMapping{
+		matches: func(err error) bool {
+			var target T
+			return errors.As(err, &target)
+		},
+		statusCode: statusCode,
+		identifier: identifier,
+	})
+}
+
+// Response returns the response of the first registered mapping matching the error
+func (e *ErrorRegistry) Response(ctx context.Context, err error) (ActionResponse, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, mapping := range e.mappings {
+		if mapping.matches(err) {
+			return ActionResponse{
+				StatusCode: mapping.statusCode,
+				Error: &ActionError{
+					Ctx:              ctx,
+					Identifier:       mapping.identifier,
+					Err:              err,
+					ValidationErrors: nil,
+				},
+			}, true
+		}
+	}
+	return ActionResponse{}, false
+}
+
+func (e *ErrorRegistry) add(mapping errorMapping) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.mappings = append(e.mappings, mapping)
+}
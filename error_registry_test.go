@@ -0,0 +1,51 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var errTestNotFound = errors.New("user not found")
+
+type testConflictError struct {
+	Id int
+}
+
+func (e *testConflictError) Error() string {
+	return fmt.Sprintf("user %d already exists", e.Id)
+}
+
+func TestErrorRegistryResponse(t *testing.T) {
+	registry := NewErrorRegistry()
+	registry.Register(errTestNotFound, http.StatusNotFound, "NotFound")
+	RegisterErrorType[*testConflictError](registry, http.StatusConflict, "Conflict")
+	ctx := context.Background()
+
+	response, ok := registry.Response(ctx, fmt.Errorf("load profile: %w", errTestNotFound))
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, ErrorIdentifier("NotFound"), response.Error.Identifier)
+	assert.Equal(t, "load profile: user not found", response.Error.Error())
+
+	response, ok = registry.Response(ctx, fmt.Errorf("register: %w", &testConflictError{Id: 1}))
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+	_, ok = registry.Response(ctx, errors.New("db is down"))
+	assert.False(t, ok)
+}
+
+func TestActionRunnerErrorResponse(t *testing.T) {
+	runner := newTestRunner(nil)
+	runner.errorMap.Register(errTestNotFound, http.StatusNotFound, "NotFound")
+	ctx := context.Background()
+
+	assert.Equal(t, http.StatusNotFound, runner.ErrorResponse(ctx, errTestNotFound).StatusCode)
+	wrappedCommon := fmt.Errorf("wrapped: %w", NewCommonError("UserBlocked", "user is blocked"))
+	assert.Equal(t, http.StatusUnprocessableEntity, runner.ErrorResponse(ctx, wrappedCommon).StatusCode)
+	assert.Equal(t, http.StatusInternalServerError, runner.ErrorResponse(ctx, errors.New("db")).StatusCode)
+}
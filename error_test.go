@@ -0,0 +1,50 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCatalogueResponses(t *testing.T) {
+	ctx := context.Background()
+	err := errors.New("failed")
+	assert.Equal(t, http.StatusUnauthorized, NewUnauthorizedResponse(ctx, err).StatusCode)
+	assert.Equal(t, http.StatusForbidden, NewForbiddenResponse(ctx, err).StatusCode)
+	assert.Equal(t, http.StatusNotFound, NewNotFoundResponse(ctx, err).StatusCode)
+	assert.Equal(t, http.StatusConflict, NewConflictResponse(ctx, err).StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, NewServiceUnavailableResponse(ctx, err, 0).StatusCode)
+	assert.Equal(t, http.StatusInternalServerError, NewActionErrorResponse(ctx, "Custom", err).StatusCode)
+
+	response := NewUnprocessableEntityResponse(ctx, NewCommonError(NotFound, "user not found"))
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, NotFound, response.Error.Identifier)
+}
+
+func TestCommonErrorsOfOriginalIdentifiersKeep422(t *testing.T) {
+	ctx := context.Background()
+	for _, identifier := range []ErrorIdentifier{WrongRequestDecoding, InvalidRequest, UnprocessableEntity, UnknownError} {
+		response := NewUnprocessableEntityResponse(ctx, NewCommonError(identifier, "failed"))
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, identifier)
+		assert.Equal(t, identifier, response.Error.Identifier)
+	}
+	assert.Equal(t, http.StatusUnprocessableEntity, NewUnprocessableEntityResponse(ctx, NewCommonError("Custom", "failed")).StatusCode)
+	assert.Equal(t, http.StatusInternalServerError, NewUnprocessableEntityResponse(ctx, errors.New("failed")).StatusCode)
+}
+
+func TestJsonResponseWriterSerialisesDetailsAndRetryHint(t *testing.T) {
+	writer := NewJsonResponseWriter(NewDefaultLogger(), NewConfig(), NewCodecRegistry())
+	response := NewTooManyRequestsResponse(context.Background(), errors.New("slow down"), 1500*time.Millisecond).
+		WithDetails(map[string]any{"limit": 10})
+
+	w := httptest.NewRecorder()
+	writer.Error(w, httptest.NewRequest(http.MethodGet, "/", nil), response)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"slow down","details":{"limit":10},"retryAfter":2}`, w.Body.String())
+}
@@ -1,8 +1,12 @@
 package application
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type JsonResponseWriter interface {
@@ -10,57 +14,114 @@ type JsonResponseWriter interface {
 	Error(w http.ResponseWriter, r *http.Request, response ActionResponse)
 }
 
+// ErrorResponseBody is the body of error responses written by DefaultJsonResponseWriter
+type ErrorResponseBody struct {
+	XMLName       struct{}           `json:"-" xml:"error"`
+	Error         string             `json:"error" xml:"message"`
+	InvalidInputs []InvalidInputBody `json:"invalidInputs,omitempty" xml:"invalidInputs>input,omitempty"`
+	Details       map[string]any     `json:"details,omitempty" xml:"-"`
+	// RetryAfter is the number of seconds after which the request may be repeated
+	RetryAfter int `json:"retryAfter,omitempty" xml:"retryAfter,omitempty"`
+}
+
+type InvalidInputBody struct {
+	Id      string `json:"id" xml:"id"`
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
+
+// DefaultJsonResponseWriter encodes responses by the codec negotiated by the Accept header of the request.
+// JSON is used if no codec is acceptable
 type DefaultJsonResponseWriter struct {
 	logger Logger
 	config *Config
+	codecs *CodecRegistry
 }
 
-func NewJsonResponseWriter(logger Logger, config *Config) JsonResponseWriter {
-	return &DefaultJsonResponseWriter{logger: logger, config: config}
+func NewJsonResponseWriter(logger Logger, config *Config, codecs *CodecRegistry) JsonResponseWriter {
+	return &DefaultJsonResponseWriter{logger: logger, config: config, codecs: codecs}
 }
 
+// Success encodes the response by the negotiated codec. If the codec cannot encode the response, JSON is used,
+// and if JSON fails too, the 500 error response is written
 func (j *DefaultJsonResponseWriter) Success(w http.ResponseWriter, r *http.Request, response ActionResponse) {
-	w.WriteHeader(response.StatusCode)
-	w.Header().Set("Content-Type", "application/json")
-
-	jsonResp, err := json.Marshal(response.Response)
+	codec, body, err := j.encode(r, response.Response)
 	if err != nil {
 		ctx := r.Context()
-		j.logger.Error(ctx, "Error happened in JSON marshal. Err: %s", err)
+		j.logger.Error(ctx, "Error happened in "+codec.MediaType()+" marshal. Err: %s", err)
+		j.Error(w, r, NewServerErrorResponse(ctx, UnknownError, errors.New("Response cannot be encoded")))
+		return
 	}
-	_, _ = w.Write(jsonResp)
+	w.Header().Set("Content-Type", codec.MediaType())
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write(body.Bytes())
 	return
 }
 
 func (j *DefaultJsonResponseWriter) Error(w http.ResponseWriter, r *http.Request, response ActionResponse) {
-	w.WriteHeader(response.StatusCode)
-	w.Header().Set("Content-Type", "application/json")
-
-	resp := make(map[string]interface{})
-	resp["error"] = "Unknown error"
+	resp := ErrorResponseBody{Error: "Unknown error"}
 	if response.Error != nil {
-		resp["error"] = response.Error.Error()
-	}
+		resp.Error = response.Error.Error()
+		resp.Details = response.Error.Details
+		resp.RetryAfter = setRetryAfter(w, response.Error.RetryAfter)
 
-	if len(response.Error.ValidationErrors) > 0 {
-		vErrors := make([]map[string]string, len(response.Error.ValidationErrors))
-		for i, validationError := range response.Error.ValidationErrors {
-			vErrors[i] = map[string]string{
-				"id":      string(validationError.Identifier),
-				"field":   string(validationError.Field),
-				"message": validationError.Err,
-			}
+		for _, validationError := range response.Error.ValidationErrors {
+			resp.InvalidInputs = append(resp.InvalidInputs, InvalidInputBody{
+				Id:      string(validationError.Identifier),
+				Field:   validationError.Field,
+				Message: validationError.Err,
+			})
 		}
-		resp["invalidInputs"] = vErrors
 	}
 
-	jsonResp, err := json.Marshal(resp)
+	codec, body, err := j.encode(r, resp)
 	if err != nil {
 		ctx := r.Context()
-		j.logger.Error(ctx, "Error happened in JSON marshal. Err: %s", err)
-		_, _ = w.Write([]byte(`{"error": "Error happened in JSON marshal."}`))
+		j.logger.Error(ctx, "Error happened in "+codec.MediaType()+" marshal. Err: %s", err)
+		w.Header().Set("Content-Type", JsonMediaType)
+		w.WriteHeader(response.StatusCode)
+		_, _ = w.Write([]byte(`{"error": "Error happened in response marshal."}`))
 		return
 	}
-	_, _ = w.Write(jsonResp)
+	w.Header().Set("Content-Type", codec.MediaType())
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write(body.Bytes())
 	return
 }
+
+// encode encodes the value by the negotiated codec and falls back to JSON if the codec fails
+func (j *DefaultJsonResponseWriter) encode(r *http.Request, v any) (Codec, *bytes.Buffer, error) {
+	codec := j.codec(r)
+	body := &bytes.Buffer{}
+	err := codec.Encode(body, v)
+	if err == nil {
+		return codec, body, nil
+	}
+	if codec.MediaType() == JsonMediaType {
+		return codec, nil, err
+	}
+	j.logger.Warn(r.Context(), "Error happened in "+codec.MediaType()+" marshal, JSON is used. Err: %s", err)
+	codec = JsonCodec{}
+	body.Reset()
+	if err := codec.Encode(body, v); err != nil {
+		return codec, nil, err
+	}
+	return codec, body, nil
+}
+
+func (j *DefaultJsonResponseWriter) codec(r *http.Request) Codec {
+	if codec, ok := j.codecs.Negotiate(r.Header.Get("Accept")); ok {
+		return codec
+	}
+	return JsonCodec{}
+}
+
+// setRetryAfter sets the Retry-After header and returns its value in seconds
+func setRetryAfter(w http.ResponseWriter, retryAfter time.Duration) int {
+	if retryAfter <= 0 {
+		return 0
+	}
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	return seconds
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// RequestIdHeader is the header the request id is taken from and echoed to
+const RequestIdHeader = "X-Request-ID"
+
+// Keys of the standard log fields
+const (
+	RequestIdLogKey = "requestId"
+	UserIdLogKey    = "userId"
+	TenantLogKey    = "tenant"
+	ModuleLogKey    = "module"
+)
+
+const maxRequestIdLength = 128
+
+type logFieldsKey struct{}
+
+// WithLogFields returns a copy of the context with the fields attached. Loggers of the package add fields
+// of the context to each message. A field replaces the field with the same key attached earlier
+func WithLogFields(ctx context.Context, fields ...slog.Attr) context.Context {
+	current := LogFields(ctx)
+	result := make([]slog.Attr, 0, len(current)+len(fields))
+	for _, field := range current {
+		if !hasLogField(fields, field.Key) {
+			result = append(result, field)
+		}
+	}
+	result = append(result, fields...)
+	return context.WithValue(ctx, logFieldsKey{}, result)
+}
+
+// LogFields returns the fields attached to the context
+func LogFields(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(logFieldsKey{}).([]slog.Attr)
+	return fields
+}
+
+// LogField returns the value of the field attached to the context
+func LogField(ctx context.Context, key string) (slog.Value, bool) {
+	for _, field := range LogFields(ctx) {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return WithLogFields(ctx, slog.String(RequestIdLogKey, requestId))
+}
+
+// RequestId returns the request id attached to the context or an empty string
+func RequestId(ctx context.Context) string {
+	if value, ok := LogField(ctx, RequestIdLogKey); ok {
+		return value.String()
+	}
+	return ""
+}
+
+func WithUserId(ctx context.Context, userId string) context.Context {
+	return WithLogFields(ctx, slog.String(UserIdLogKey, userId))
+}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return WithLogFields(ctx, slog.String(TenantLogKey, tenant))
+}
+
+func WithModule(ctx context.Context, module string) context.Context {
+	return WithLogFields(ctx, slog.String(ModuleLogKey, module))
+}
+
+// RequestIdMiddleware attaches the request id from the X-Request-ID header to the request context
+// or generates a new one if the header is empty or malformed. The id is echoed in the response headers.
+// The application wraps all routes with this middleware
+func RequestIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(RequestIdHeader)
+		if !isValidRequestId(requestId) {
+			requestId = newRequestId()
+		}
+		w.Header().Set(RequestIdHeader, requestId)
+		next.ServeHTTP(w, r.WithContext(WithRequestId(r.Context(), requestId)))
+	})
+}
+
+// moduleLogMiddleware attaches the module name to contexts of requests handled by the module routes
+func moduleLogMiddleware(module string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithModule(r.Context(), module)))
+		})
+	}
+}
+
+// isValidRequestId allows only printable ASCII ids of a limited length, so a client cannot break log lines
+func isValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, c := range requestId {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func hasLogField(fields []slog.Attr, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// formatLogFields formats fields of the context as key=value pairs for plain text loggers
+func formatLogFields(ctx context.Context) string {
+	fields := LogFields(ctx)
+	if len(fields) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		pairs[i] = field.String()
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,115 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithLogFields(t *testing.T) {
+	ctx := WithRequestId(context.Background(), "req-1")
+	ctx = WithUserId(ctx, "42")
+	ctx = WithTenant(ctx, "acme")
+	ctx = WithLogFields(ctx, slog.String(UserIdLogKey, "43"))
+
+	assert.Equal(t, "req-1", RequestId(ctx))
+	assert.Equal(t, []slog.Attr{
+		slog.String(RequestIdLogKey, "req-1"),
+		slog.String(TenantLogKey, "acme"),
+		slog.String(UserIdLogKey, "43"),
+	}, LogFields(ctx))
+	assert.Equal(t, "", RequestId(context.Background()))
+}
+
+func TestRequestIdMiddlewareTakesHeader(t *testing.T) {
+	var requestId string
+	handler := RequestIdMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId = RequestId(r.Context())
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIdHeader, "abc-123")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, "abc-123", requestId)
+	assert.Equal(t, "abc-123", w.Header().Get(RequestIdHeader))
+}
+
+func TestRequestIdMiddlewareGeneratesId(t *testing.T) {
+	var requestId string
+	handler := RequestIdMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId = RequestId(r.Context())
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Len(t, requestId, 32)
+	assert.Equal(t, requestId, w.Header().Get(RequestIdHeader))
+}
+
+func TestRequestIdMiddlewareReplacesMalformedHeader(t *testing.T) {
+	var requestId string
+	handler := RequestIdMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId = RequestId(r.Context())
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIdHeader, "abc\ninjected=1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Len(t, requestId, 32)
+	assert.Equal(t, requestId, w.Header().Get(RequestIdHeader))
+}
+
+func TestDefaultLoggerIncludesContextFields(t *testing.T) {
+	ctx := WithModule(WithRequestId(context.Background(), "req-1"), "billing")
+	buf := captureLog(t)
+
+	NewDefaultLogger().Info(ctx, "paid %d", 5)
+
+	assert.Equal(t, "INFO: paid 5 requestId=req-1 module=billing\n", buf.String())
+}
+
+func TestSlogLoggerIncludesContextFields(t *testing.T) {
+	ctx := WithModule(WithRequestId(context.Background(), "req-1"), "billing")
+	buf := &bytes.Buffer{}
+
+	NewSlogLoggerWithHandler(NewSlogHandler(buf, "json", slog.LevelInfo)).Info(ctx, "paid", slog.Int("amount", 5))
+
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "req-1", line[RequestIdLogKey])
+	assert.Equal(t, "billing", line[ModuleLogKey])
+	assert.Equal(t, float64(5), line["amount"])
+}
+
+func TestApplicationSeedsRequestLogFields(t *testing.T) {
+	var calls []string
+	var fields []slog.Attr
+	app := New([]interface{}{&MiddlewareSp{calls: &calls}})
+	app.setDefaults()
+	err := app.Container().Invoke(func(global *GlobalMiddlewares) {
+		global.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fields = LogFields(r.Context())
+				next.ServeHTTP(w, r)
+			})
+		})
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, app.initHttpRoutes())
+
+	r := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	r.Header.Set(RequestIdHeader, "req-7")
+	w := httptest.NewRecorder()
+	app.getRouter().(*DefaultRouter).ServeHTTP(w, r)
+
+	assert.Equal(t, "req-7", w.Header().Get(RequestIdHeader))
+	assert.Equal(t, []slog.Attr{slog.String(RequestIdLogKey, "req-7")}, fields)
+}
@@ -2,14 +2,18 @@ package application
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
 )
 
 type Logger interface {
 	Debug(ctx context.Context, s string, i ...interface{})
 	Info(ctx context.Context, s string, i ...interface{})
 	Warn(ctx context.Context, s string, i ...interface{})
+	// Error logs the message without stopping the application
 	Error(ctx context.Context, s string, i ...interface{})
+	// Panic logs the message and panics with it
 	Panic(ctx context.Context, s string, i ...interface{})
 }
 
@@ -21,21 +25,84 @@ func NewDefaultLogger() Logger {
 }
 
 func (d *DefaultLogger) Debug(ctx context.Context, s string, i ...interface{}) {
-	log.Println("DEBUG: ", s, i)
+	log.Println("DEBUG:", formatLogMessage(ctx, s, i))
 }
 
 func (d *DefaultLogger) Info(ctx context.Context, s string, i ...interface{}) {
-	log.Println("INFO: ", s, i)
+	log.Println("INFO:", formatLogMessage(ctx, s, i))
 }
 
 func (d *DefaultLogger) Warn(ctx context.Context, s string, i ...interface{}) {
-	log.Println("WARN: ", s, i)
+	log.Println("WARN:", formatLogMessage(ctx, s, i))
 }
 
 func (d *DefaultLogger) Error(ctx context.Context, s string, i ...interface{}) {
-	log.Fatalln("ERROR: ", s, i)
+	log.Println("ERROR:", formatLogMessage(ctx, s, i))
 }
 
 func (d *DefaultLogger) Panic(ctx context.Context, s string, i ...interface{}) {
-	log.Fatalln("PANIC: ", s, i)
+	message := formatLogMessage(ctx, s, i)
+	log.Println("PANIC:", message)
+	panic(message)
+}
+
+// formatLogMessage formats the message by arguments if it contains formatting verbs,
+// the rest of arguments are appended to the message. Log fields of the context are appended in the end
+func formatLogMessage(ctx context.Context, s string, i []interface{}) string {
+	message, rest := formatLogArgs(s, i, func(arg interface{}) bool { return true })
+	if len(rest) > 0 {
+		message += " " + strings.TrimSuffix(fmt.Sprintln(rest...), "\n")
+	}
+	if fields := formatLogFields(ctx); fields != "" {
+		message += " " + fields
+	}
+	return message
+}
+
+// formatVerbs are the verbs of the fmt package
+const formatVerbs = "vTtbcdoOqxXUeEfFgGsp"
+
+// formatLogArgs formats the message by as many arguments as there are formatting verbs in it,
+// so messages like "disk is 90% full" are not treated as format strings.
+// Only arguments accepted by isFormatArg are used for formatting, the rest of arguments are returned
+func formatLogArgs(s string, i []interface{}, isFormatArg func(arg interface{}) bool) (string, []interface{}) {
+	verbs := countFormatVerbs(s)
+	if verbs == 0 {
+		return s, i
+	}
+	var formatArgs []interface{}
+	var rest []interface{}
+	for _, arg := range i {
+		if len(formatArgs) < verbs && isFormatArg(arg) {
+			formatArgs = append(formatArgs, arg)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	if len(formatArgs) == 0 {
+		return s, rest
+	}
+	return fmt.Sprintf(s, formatArgs...), rest
+}
+
+// countFormatVerbs returns the number of arguments the format string consumes, %% is not counted
+func countFormatVerbs(s string) int {
+	count := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(s) && strings.IndexByte("+-#0123456789.*[]", s[j]) >= 0 {
+			if s[j] == '*' {
+				count++
+			}
+			j++
+		}
+		if j < len(s) && strings.IndexByte(formatVerbs, s[j]) >= 0 {
+			count++
+		}
+		i = j
+	}
+	return count
 }
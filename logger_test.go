@@ -0,0 +1,61 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"os"
+	"testing"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	flags := log.Flags()
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	})
+	return buf
+}
+
+func TestDefaultLoggerError(t *testing.T) {
+	buf := captureLog(t)
+	logger := NewDefaultLogger()
+
+	logger.Error(context.Background(), "Error happened in JSON marshal. Err: %s", "broken")
+
+	assert.Equal(t, "ERROR: Error happened in JSON marshal. Err: broken\n", buf.String())
+}
+
+func TestDefaultLoggerInfo(t *testing.T) {
+	buf := captureLog(t)
+	logger := NewDefaultLogger()
+
+	logger.Info(context.Background(), "started")
+	logger.Info(context.Background(), "listening", ":8080")
+
+	assert.Equal(t, "INFO: started\nINFO: listening :8080\n", buf.String())
+}
+
+func TestDefaultLoggerPanic(t *testing.T) {
+	buf := captureLog(t)
+	logger := NewDefaultLogger()
+
+	assert.PanicsWithValue(t, "broken route 1", func() {
+		logger.Panic(context.Background(), "broken route %d", 1)
+	})
+	assert.Equal(t, "PANIC: broken route 1\n", buf.String())
+}
+
+func TestDefaultLoggerKeepsPercentWithoutVerbs(t *testing.T) {
+	buf := captureLog(t)
+	logger := NewDefaultLogger()
+
+	logger.Warn(context.Background(), "disk is 90% full", "/var")
+	logger.Warn(context.Background(), "%d%% of %s is used", 90, "disk", "/var")
+
+	assert.Equal(t, "WARN: disk is 90% full /var\nWARN: 90% of disk is used /var\n", buf.String())
+}
@@ -0,0 +1,47 @@
+package application
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Middleware wraps a http handler to add cross-cutting behaviour like auth, logging or CORS
+type Middleware func(http.Handler) http.Handler
+
+// HttpMiddlewaresInitializer if service provider implements this method the middlewares
+// will be applied to all routes of the module
+type HttpMiddlewaresInitializer interface {
+	// ModuleMiddlewares Returns middlewares wrapping the routes of the module
+	ModuleMiddlewares() []Middleware
+}
+
+// GlobalMiddlewares holds middlewares applied to all routes of the application.
+// Modules can take it from the container and add middlewares until routes initialization
+type GlobalMiddlewares struct {
+	mu          sync.Mutex
+	middlewares []Middleware
+}
+
+func NewGlobalMiddlewares() *GlobalMiddlewares {
+	return &GlobalMiddlewares{}
+}
+
+func (g *GlobalMiddlewares) Use(middlewares ...Middleware) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.middlewares = append(g.middlewares, middlewares...)
+}
+
+func (g *GlobalMiddlewares) Middlewares() []Middleware {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Middleware(nil), g.middlewares...)
+}
+
+// Chain wraps the handler with middlewares. The first middleware is the outermost one
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,56 @@
+package application
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewaresOrder(t *testing.T) {
+	var calls []string
+	module := &MiddlewareSp{calls: &calls}
+	app := New([]interface{}{module})
+	app.setDefaults()
+	err := app.Container().Invoke(func(global *GlobalMiddlewares) {
+		global.Use(tracingMiddleware("global", &calls))
+	})
+	assert.Nil(t, err)
+
+	err = app.initHttpRoutes()
+	assert.Nil(t, err)
+
+	router := app.getRouter().(*DefaultRouter)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, []string{"global", "module", "routes", "route", "handler"}, calls)
+}
+
+func tracingMiddleware(name string, calls *[]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type MiddlewareSp struct {
+	calls *[]string
+}
+
+func (m *MiddlewareSp) ModuleMiddlewares() []Middleware {
+	return []Middleware{tracingMiddleware("module", m.calls)}
+}
+
+func (m *MiddlewareSp) ModuleRoutes() []RouteInfo {
+	routes := NewRoutes()
+	routes.Use(tracingMiddleware("routes", m.calls))
+	routes.Get(
+		"/ping",
+		func(w http.ResponseWriter, r *http.Request) {
+			*m.calls = append(*m.calls, "handler")
+		},
+		tracingMiddleware("route", m.calls),
+	)
+	return routes.GetRoutesInfo()
+}
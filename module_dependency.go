@@ -0,0 +1,132 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var ErrMissingModule = errors.New("module dependency is not registered")
+var ErrModuleCycle = errors.New("module dependencies have a cycle")
+var ErrAmbiguousModule = errors.New("module dependency matches several modules")
+
+// DependentModule allows a module config to declare modules it depends on.
+// Dependencies are started before the module and closed after it
+type DependentModule interface {
+	// DependsOn returns module configs or package paths of modules that the module depends on.
+	// A module config is matched by its type, for example (*billing.ModuleConfig)(nil).
+	// A package path should match exactly one module besides the dependent one
+	DependsOn() []interface{}
+}
+
+// sortModules returns module configs in the order of their dependencies.
+// Modules without dependencies between them keep the order given to the application
+func sortModules(moduleConfigs []interface{}) ([]interface{}, error) {
+	deps := make([][]int, len(moduleConfigs))
+	for i, moduleConfig := range moduleConfigs {
+		dependent, ok := moduleConfig.(DependentModule)
+		if !ok {
+			continue
+		}
+		for _, dependency := range dependent.DependsOn() {
+			index, err := findModule(moduleConfigs, i, dependency)
+			if err != nil {
+				return nil, NewModuleError(moduleConfig, "dependencies resolving", err)
+			}
+			deps[i] = append(deps[i], index)
+		}
+	}
+
+	const (
+		notVisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(moduleConfigs))
+	result := make([]interface{}, 0, len(moduleConfigs))
+	var path []int
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return NewModuleError(moduleConfigs[i], "dependencies resolving", cycleError(moduleConfigs, path, i))
+		}
+		state[i] = visiting
+		path = append(path, i)
+		for _, dep := range deps[i] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		result = append(result, moduleConfigs[i])
+		return nil
+	}
+	for i := range moduleConfigs {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// findModule returns the index of the module matching the dependency of the module with the index self
+func findModule(moduleConfigs []interface{}, self int, dependency interface{}) (int, error) {
+	name, byName := dependency.(string)
+	depType := moduleType(dependency)
+	found := -1
+	for i, moduleConfig := range moduleConfigs {
+		if byName && (i == self || ModuleName(moduleConfig) != name) {
+			continue
+		}
+		if !byName && moduleType(moduleConfig) != depType {
+			continue
+		}
+		if found >= 0 {
+			return -1, fmt.Errorf("%w: %s", ErrAmbiguousModule, dependencyName(dependency))
+		}
+		found = i
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("%w: %s", ErrMissingModule, dependencyName(dependency))
+	}
+	return found, nil
+}
+
+func moduleType(moduleConfig interface{}) reflect.Type {
+	t := reflect.TypeOf(moduleConfig)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func dependencyName(dependency interface{}) string {
+	if name, ok := dependency.(string); ok {
+		return name
+	}
+	if t := moduleType(dependency); t != nil {
+		return t.String()
+	}
+	return "nil"
+}
+
+func cycleError(moduleConfigs []interface{}, path []int, start int) error {
+	names := make([]string, 0, len(path)+1)
+	for j := len(path) - 1; j >= 0; j-- {
+		names = append(names, dependencyName(moduleConfigs[path[j]]))
+		if path[j] == start {
+			break
+		}
+	}
+	for l, r := 0, len(names)-1; l < r; l, r = l+1, r-1 {
+		names[l], names[r] = names[r], names[l]
+	}
+	names = append(names, dependencyName(moduleConfigs[start]))
+	return fmt.Errorf("%w: %s", ErrModuleCycle, strings.Join(names, " -> "))
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSortModulesByDependencies(t *testing.T) {
+	http := &HttpModule{}
+	db := &DbModule{}
+	cache := &CacheModule{}
+
+	sorted, err := sortModules([]interface{}{cache, http, db})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{db, http, cache}, sorted)
+}
+
+func TestSortModulesByPackagePath(t *testing.T) {
+	packageDependent := &PackageDependentModule{}
+	config := NewConfig()
+
+	sorted, err := sortModules([]interface{}{packageDependent, config})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{config, packageDependent}, sorted)
+}
+
+func TestSortModulesAmbiguousPackagePath(t *testing.T) {
+	_, err := sortModules([]interface{}{&PackageDependentModule{}, &DbModule{}, NewConfig()})
+	assert.ErrorIs(t, err, ErrAmbiguousModule)
+}
+
+func TestSortModulesMissingDependency(t *testing.T) {
+	_, err := sortModules([]interface{}{&HttpModule{}})
+	assert.ErrorIs(t, err, ErrMissingModule)
+}
+
+func TestSortModulesCycle(t *testing.T) {
+	_, err := sortModules([]interface{}{&CycleModuleA{}, &CycleModuleB{}})
+	assert.ErrorIs(t, err, ErrModuleCycle)
+	assert.Contains(t, err.Error(), "CycleModuleA -> application.CycleModuleB -> application.CycleModuleA")
+}
+
+type DbModule struct {
+}
+
+type HttpModule struct {
+}
+
+func (m *HttpModule) DependsOn() []interface{} {
+	return []interface{}{(*DbModule)(nil)}
+}
+
+type CacheModule struct {
+}
+
+func (m *CacheModule) DependsOn() []interface{} {
+	return []interface{}{(*HttpModule)(nil)}
+}
+
+type PackageDependentModule struct {
+}
+
+func (m *PackageDependentModule) DependsOn() []interface{} {
+	return []interface{}{"github.com/debugger84/modulus-application"}
+}
+
+type CycleModuleA struct {
+}
+
+func (m *CycleModuleA) DependsOn() []interface{} {
+	return []interface{}{(*CycleModuleB)(nil)}
+}
+
+type CycleModuleB struct {
+}
+
+func (m *CycleModuleB) DependsOn() []interface{} {
+	return []interface{}{(*CycleModuleA)(nil)}
+}
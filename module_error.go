@@ -0,0 +1,38 @@
+package application
+
+import (
+	"reflect"
+)
+
+// ModuleError describes a failure of a module on one of the application lifecycle steps
+type ModuleError struct {
+	// Module is the package path of the module config
+	Module string
+	// Stage is the lifecycle step where the error happened
+	Stage string
+	Err   error
+}
+
+func NewModuleError(moduleConfig interface{}, stage string, err error) *ModuleError {
+	return &ModuleError{Module: ModuleName(moduleConfig), Stage: stage, Err: err}
+}
+
+func (e *ModuleError) Error() string {
+	return e.Module + ": " + e.Stage + " failed: " + e.Err.Error()
+}
+
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
+// ModuleName returns the package path of the module config
+func ModuleName(moduleConfig interface{}) string {
+	t := reflect.TypeOf(moduleConfig)
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.PkgPath()
+}
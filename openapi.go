@@ -0,0 +1,506 @@
+package application
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const openApiVersion = "3.1.0"
+
+// RouteDoc describes a route for the OpenAPI document
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Request is the request structure of the route, for example RegisterRequest{}.
+	// Parameters are taken from path, query, header, cookie and qs tags, the request body from json tags
+	// and constraints from validate tags
+	Request any
+	// Responses maps status codes to response structures
+	Responses map[int]any
+}
+
+type OpenApiInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenApiDocument struct {
+	OpenApi    string                                  `json:"openapi"`
+	Info       OpenApiInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenApiOperation `json:"paths"`
+	Components OpenApiComponents                       `json:"components"`
+}
+
+type OpenApiComponents struct {
+	Schemas map[string]*OpenApiSchema `json:"schemas"`
+}
+
+type OpenApiOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenApiParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenApiRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenApiResponse `json:"responses"`
+}
+
+type OpenApiParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenApiSchema `json:"schema"`
+}
+
+type OpenApiRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenApiMediaType `json:"content"`
+}
+
+type OpenApiMediaType struct {
+	Schema *OpenApiSchema `json:"schema"`
+}
+
+type OpenApiResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenApiMediaType `json:"content,omitempty"`
+}
+
+type OpenApiSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Properties           map[string]*OpenApiSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *OpenApiSchema            `json:"items,omitempty"`
+	AdditionalProperties *OpenApiSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64                  `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64                  `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+}
+
+// NewOpenApiDocument generates the OpenAPI document describing the routes
+func NewOpenApiDocument(info OpenApiInfo, routes []RouteInfo) *OpenApiDocument {
+	g := &openApiGenerator{
+		doc: &OpenApiDocument{
+			OpenApi:    openApiVersion,
+			Info:       info,
+			Paths:      make(map[string]map[string]*OpenApiOperation),
+			Components: OpenApiComponents{Schemas: make(map[string]*OpenApiSchema)},
+		},
+		types: make(map[string]reflect.Type),
+	}
+	g.doc.Components.Schemas["Error"] = errorSchema()
+	for _, route := range routes {
+		g.addRoute(route)
+	}
+	return g.doc
+}
+
+// WriteFile writes the document to the file as indented json
+func (d *OpenApiDocument) WriteFile(filename string) error {
+	content, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0644)
+}
+
+// Handler returns a handler serving the document as json
+func (d *OpenApiDocument) Handler() http.HandlerFunc {
+	content, err := json.Marshal(d)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(content)
+	}
+}
+
+// errorSchema describes the body written by DefaultJsonResponseWriter.Error
+func errorSchema() *OpenApiSchema {
+	return &OpenApiSchema{
+		Type: "object",
+		Properties: map[string]*OpenApiSchema{
+			"error":      {Type: "string"},
+			"details":    {Type: "object"},
+			"retryAfter": {Type: "integer", Format: "int32"},
+			"invalidInputs": {
+				Type: "array",
+				Items: &OpenApiSchema{
+					Type: "object",
+					Properties: map[string]*OpenApiSchema{
+						"id":      {Type: "string"},
+						"field":   {Type: "string"},
+						"message": {Type: "string"},
+					},
+				},
+			},
+		},
+		Required: []string{"error"},
+	}
+}
+
+type openApiGenerator struct {
+	doc   *OpenApiDocument
+	types map[string]reflect.Type
+}
+
+func (g *openApiGenerator) addRoute(route RouteInfo) {
+	path := pathWildcardRegexp.ReplaceAllString(route.path, "{$1}")
+	path = strings.ReplaceAll(path, "{$}", "")
+	operation := &OpenApiOperation{Responses: make(map[string]OpenApiResponse)}
+	doc := route.doc
+	if doc == nil {
+		doc = &RouteDoc{}
+	}
+	operation.Summary = doc.Summary
+	operation.Description = doc.Description
+	operation.Tags = doc.Tags
+
+	var pathParams []string
+	for _, match := range pathWildcardRegexp.FindAllStringSubmatch(route.path, -1) {
+		pathParams = append(pathParams, match[1])
+	}
+	g.addRequest(operation, route.method, pathParams, doc.Request)
+
+	for code, response := range doc.Responses {
+		r := OpenApiResponse{Description: http.StatusText(code)}
+		if response != nil {
+			r.Content = jsonContent(g.schema(reflect.TypeOf(response)))
+		}
+		operation.Responses[strconv.Itoa(code)] = r
+	}
+	if len(doc.Responses) == 0 {
+		operation.Responses["200"] = OpenApiResponse{Description: http.StatusText(http.StatusOK)}
+	}
+	errorContent := jsonContent(&OpenApiSchema{Ref: "#/components/schemas/Error"})
+	if doc.Request != nil {
+		operation.Responses["400"] = OpenApiResponse{Description: "Invalid request", Content: errorContent}
+	}
+	operation.Responses["default"] = OpenApiResponse{Description: "Error", Content: errorContent}
+
+	if g.doc.Paths[path] == nil {
+		g.doc.Paths[path] = make(map[string]*OpenApiOperation)
+	}
+	g.doc.Paths[path][strings.ToLower(route.method)] = operation
+}
+
+func (g *openApiGenerator) addRequest(
+	operation *OpenApiOperation,
+	method string,
+	pathParams []string,
+	request any,
+) {
+	t := derefType(reflect.TypeOf(request))
+	found := make(map[string]bool)
+	isPathParam := make(map[string]bool)
+	for _, name := range pathParams {
+		isPathParam[name] = true
+	}
+	if t != nil && t.Kind() == reflect.Struct {
+		hasBody := method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+		for _, field := range structFields(t) {
+			if g.addTaggedParameters(operation, field, found) {
+				continue
+			}
+			name, required, ok := qsFieldName(field)
+			if !ok {
+				continue
+			}
+			in := "query"
+			if isPathParam[name] {
+				in = "path"
+				found[name] = true
+			} else if hasBody {
+				continue
+			}
+			schema := g.schema(field.Type)
+			required = g.applyValidation(schema, field) || required
+			operation.Parameters = append(operation.Parameters, OpenApiParameter{
+				Name:     name,
+				In:       in,
+				Required: required || in == "path",
+				Schema:   schema,
+			})
+		}
+		if hasBody {
+			operation.RequestBody = &OpenApiRequestBody{
+				Required: true,
+				Content:  jsonContent(g.schema(t)),
+			}
+		}
+	}
+	for _, name := range pathParams {
+		if !found[name] {
+			operation.Parameters = append(operation.Parameters, OpenApiParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &OpenApiSchema{Type: "string"},
+			})
+		}
+	}
+}
+
+// addTaggedParameters adds parameters of the field marked by path, query, header or cookie tags
+// and returns false if the field has no such tags
+func (g *openApiGenerator) addTaggedParameters(
+	operation *OpenApiOperation,
+	field reflect.StructField,
+	found map[string]bool,
+) bool {
+	tagged := false
+	for i := len(paramSources) - 1; i >= 0; i-- {
+		in := paramSources[i]
+		name, ok := field.Tag.Lookup(in)
+		if !ok || name == "" {
+			continue
+		}
+		tagged = true
+		if in == PathSource {
+			found[name] = true
+		}
+		schema := g.schema(field.Type)
+		required := g.applyValidation(schema, field)
+		operation.Parameters = append(operation.Parameters, OpenApiParameter{
+			Name:     name,
+			In:       in,
+			Required: required || in == PathSource,
+			Schema:   schema,
+		})
+	}
+	return tagged
+}
+
+func (g *openApiGenerator) schema(t reflect.Type) *OpenApiSchema {
+	t = derefType(t)
+	if t == nil {
+		return &OpenApiSchema{}
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return &OpenApiSchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenApiSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenApiSchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenApiSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenApiSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenApiSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenApiSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenApiSchema{Type: "string", Format: "byte"}
+		}
+		return &OpenApiSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenApiSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &OpenApiSchema{Ref: "#/components/schemas/" + g.componentName(t)}
+	}
+	return &OpenApiSchema{}
+}
+
+func (g *openApiGenerator) componentName(t reflect.Type) string {
+	name := t.Name()
+	for i := 2; ; i++ {
+		registered, exists := g.types[name]
+		if !exists {
+			g.types[name] = t
+			g.doc.Components.Schemas[name] = &OpenApiSchema{}
+			*g.doc.Components.Schemas[name] = *g.structSchema(t)
+			return name
+		}
+		if registered == t {
+			return name
+		}
+		name = t.Name() + strconv.Itoa(i)
+	}
+}
+
+func (g *openApiGenerator) structSchema(t reflect.Type) *OpenApiSchema {
+	schema := &OpenApiSchema{Type: "object", Properties: make(map[string]*OpenApiSchema)}
+	for _, field := range structFields(t) {
+		name, ok := jsonFieldName(field)
+		// fields with source tags are never decoded from the body, they are documented as parameters
+		if !ok || hasParamTag(field) {
+			continue
+		}
+		fieldSchema := g.schema(field.Type)
+		required := g.applyValidation(fieldSchema, field)
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = fieldSchema
+	}
+	return schema
+}
+
+// applyValidation adds constraints of the validate tag to the schema and returns true if the field is required
+func (g *openApiGenerator) applyValidation(schema *OpenApiSchema, field reflect.StructField) bool {
+	required := false
+	kind := derefType(field.Type).Kind()
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		number, numberErr := strconv.ParseFloat(param, 64)
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "min", "gte", "max", "lte", "len", "gt", "lt":
+			if numberErr != nil {
+				continue
+			}
+			applyLimit(schema, kind, name, number)
+		}
+	}
+	return required
+}
+
+func applyLimit(schema *OpenApiSchema, kind reflect.Kind, rule string, number float64) {
+	count := int(number)
+	switch kind {
+	case reflect.String:
+		switch rule {
+		case "min", "gte":
+			schema.MinLength = &count
+		case "max", "lte":
+			schema.MaxLength = &count
+		case "len":
+			schema.MinLength, schema.MaxLength = &count, &count
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		switch rule {
+		case "min", "gte":
+			schema.MinItems = &count
+		case "max", "lte":
+			schema.MaxItems = &count
+		case "len":
+			schema.MinItems, schema.MaxItems = &count, &count
+		}
+	default:
+		switch rule {
+		case "min", "gte":
+			schema.Minimum = &number
+		case "max", "lte":
+			schema.Maximum = &number
+		case "gt":
+			schema.ExclusiveMinimum = &number
+		case "lt":
+			schema.ExclusiveMaximum = &number
+		}
+	}
+}
+
+func jsonContent(schema *OpenApiSchema) map[string]OpenApiMediaType {
+	return map[string]OpenApiMediaType{"application/json": {Schema: schema}}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// structFields returns exported fields of the struct including fields of embedded structs
+func structFields(t reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		embedded := derefType(field.Type)
+		if field.Anonymous && embedded.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			fields = append(fields, structFields(embedded)...)
+			continue
+		}
+		if field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func jsonFieldName(field reflect.StructField) (name string, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// qsFieldName returns the name of the field used by qs.Unmarshal and true if the field is marked as required
+func qsFieldName(field reflect.StructField) (name string, required bool, ok bool) {
+	parts := strings.Split(field.Tag.Get("qs"), ",")
+	if parts[0] == "-" {
+		return "", false, false
+	}
+	name = parts[0]
+	if name == "" {
+		name = snakeCase(field.Name)
+	}
+	for _, option := range parts[1:] {
+		if option == "req" {
+			required = true
+		}
+	}
+	return name, required, true
+}
+
+// snakeCase converts CamelCase names to snake_case the same way as the qs package does
+func snakeCase(s string) string {
+	in := []rune(s)
+	isLower := func(idx int) bool {
+		return idx >= 0 && idx < len(in) && unicode.IsLower(in[idx])
+	}
+
+	out := make([]rune, 0, len(in)+len(in)/2)
+	for i, r := range in {
+		if unicode.IsUpper(r) {
+			r = unicode.ToLower(r)
+			if i > 0 && in[i-1] != '_' && (isLower(i-1) || isLower(i+1)) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, r)
+	}
+
+	return string(out)
+}
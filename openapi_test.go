@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type openApiUserRequest struct {
+	Id    int    `qs:"id" json:"-"`
+	Name  string `json:"name" validate:"required,min=3,max=20"`
+	Email string `json:"email,omitempty" validate:"email"`
+	Role  string `json:"role" validate:"oneof=admin user"`
+}
+
+type openApiListRequest struct {
+	Page    int    `qs:"page" validate:"gte=1"`
+	OrderBy string `qs:"order_by,req"`
+}
+
+type openApiUser struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestNewOpenApiDocument(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	routes := NewRoutes()
+	routes.Put("/users/{id}", handler).Describe(RouteDoc{
+		Summary:   "Update a user",
+		Request:   openApiUserRequest{},
+		Responses: map[int]any{http.StatusOK: openApiUser{}},
+	})
+	routes.Get("/users", handler).Describe(RouteDoc{
+		Request:   &openApiListRequest{},
+		Responses: map[int]any{http.StatusOK: []openApiUser{}},
+	})
+	routes.Delete("/users/{id}", handler)
+
+	doc := NewOpenApiDocument(OpenApiInfo{Title: "Users", Version: "1.0"}, routes.GetRoutesInfo())
+
+	assert.Equal(t, "3.1.0", doc.OpenApi)
+	update := doc.Paths["/users/{id}"]["put"]
+	assert.Equal(t, "Update a user", update.Summary)
+	assert.Equal(t, []OpenApiParameter{
+		{Name: "id", In: "path", Required: true, Schema: &OpenApiSchema{Type: "integer", Format: "int32"}},
+	}, update.Parameters)
+	assert.Equal(
+		t,
+		"#/components/schemas/openApiUserRequest",
+		update.RequestBody.Content["application/json"].Schema.Ref,
+	)
+	assert.Equal(t, "#/components/schemas/Error", update.Responses["400"].Content["application/json"].Schema.Ref)
+
+	userSchema := doc.Components.Schemas["openApiUserRequest"]
+	assert.Equal(t, []string{"name"}, userSchema.Required)
+	assert.Equal(t, 3, *userSchema.Properties["name"].MinLength)
+	assert.Equal(t, 20, *userSchema.Properties["name"].MaxLength)
+	assert.Equal(t, "email", userSchema.Properties["email"].Format)
+	assert.Equal(t, []any{"admin", "user"}, userSchema.Properties["role"].Enum)
+	assert.NotContains(t, userSchema.Properties, "Id")
+
+	list := doc.Paths["/users"]["get"]
+	assert.Len(t, list.Parameters, 2)
+	assert.Equal(t, "query", list.Parameters[0].In)
+	assert.Equal(t, float64(1), *list.Parameters[0].Schema.Minimum)
+	assert.True(t, list.Parameters[1].Required)
+	assert.Equal(t, "array", list.Responses["200"].Content["application/json"].Schema.Type)
+
+	remove := doc.Paths["/users/{id}"]["delete"]
+	assert.Equal(t, "string", remove.Parameters[0].Schema.Type)
+}
+
+type openApiTaggedRequest struct {
+	Id     int    `path:"id" json:"id"`
+	Tenant string `header:"X-Tenant"`
+	Name   string `json:"name"`
+}
+
+func TestNewOpenApiDocumentSkipsTaggedParamsInBody(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	routes := NewRoutes()
+	routes.Put("/users/{id}", handler).Describe(RouteDoc{Request: &openApiTaggedRequest{}})
+
+	doc := NewOpenApiDocument(OpenApiInfo{Title: "Users", Version: "1.0"}, routes.GetRoutesInfo())
+
+	update := doc.Paths["/users/{id}"]["put"]
+	assert.Len(t, update.Parameters, 2)
+	assert.Equal(t, "path", update.Parameters[0].In)
+	assert.Equal(t, "header", update.Parameters[1].In)
+	schema := doc.Components.Schemas["openApiTaggedRequest"]
+	assert.Contains(t, schema.Properties, "name")
+	assert.NotContains(t, schema.Properties, "id")
+	assert.NotContains(t, schema.Properties, "Tenant")
+}
+
+func TestNewOpenApiDocumentOfHandleRoutes(t *testing.T) {
+	runner := newTestRunner(NewDefaultRouter(NewConfig()))
+	routes := NewRoutes()
+	routes.Handle(
+		http.MethodPut,
+		"/users/{id}",
+		HandleDocumented(runner, func(ctx context.Context, request *openApiUserRequest) (openApiUser, error) {
+			return openApiUser{}, nil
+		}),
+	)
+	routes.Handle(
+		http.MethodGet,
+		"/users",
+		HandleDocumented(runner, func(ctx context.Context, request *openApiListRequest) ([]openApiUser, error) {
+			return nil, nil
+		}),
+	).Describe(RouteDoc{Summary: "List users", Responses: map[int]any{http.StatusPartialContent: []openApiUser{}}})
+
+	doc := NewOpenApiDocument(OpenApiInfo{Title: "Users", Version: "1.0"}, routes.GetRoutesInfo())
+
+	update := doc.Paths["/users/{id}"]["put"]
+	assert.Equal(t, "id", update.Parameters[0].Name)
+	assert.Equal(
+		t,
+		"#/components/schemas/openApiUserRequest",
+		update.RequestBody.Content["application/json"].Schema.Ref,
+	)
+	assert.Equal(t, "#/components/schemas/openApiUser", update.Responses["200"].Content["application/json"].Schema.Ref)
+
+	list := doc.Paths["/users"]["get"]
+	assert.Equal(t, "List users", list.Summary)
+	assert.Len(t, list.Parameters, 2)
+	assert.NotContains(t, list.Responses, "200")
+	assert.Equal(t, "array", list.Responses["206"].Content["application/json"].Schema.Type)
+}
+
+func TestApplicationEnableOpenApi(t *testing.T) {
+	var calls []string
+	app := New([]interface{}{&MiddlewareSp{calls: &calls}})
+	app.EnableOpenApi("/openapi.json", OpenApiInfo{Title: "Test", Version: "1.0"})
+	app.setDefaults()
+	err := app.initHttpRoutes()
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	router := app.getRouter().(*DefaultRouter)
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	var doc OpenApiDocument
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Contains(t, doc.Paths, "/ping")
+}
+
+func TestApplicationEnableOpenApiReturnsRouteConflict(t *testing.T) {
+	var calls []string
+	app := New([]interface{}{&MiddlewareSp{calls: &calls}})
+	app.EnableOpenApi("/ping", OpenApiInfo{Title: "Test", Version: "1.0"})
+
+	err := app.Run()
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "openapi route initialisation")
+	}
+}
@@ -0,0 +1,102 @@
+package application
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const ProblemJsonMediaType = "application/problem+json"
+
+// ProblemDetails is the RFC 7807 body of error responses written by ProblemDetailsResponseWriter
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is the extension member holding the identifier of the error
+	Code ErrorIdentifier `json:"code,omitempty"`
+	// Errors is the extension member holding validation errors
+	Errors []InvalidInputBody `json:"errors,omitempty"`
+	// Details is the extension member holding machine-readable details of the error
+	Details map[string]any `json:"details,omitempty"`
+	// RetryAfter is the extension member holding the number of seconds after which the request may be repeated
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+// ProblemDetailsResponseWriter writes errors as application/problem+json and successful responses
+// the same way as DefaultJsonResponseWriter. To use it instead of the default writer
+// return NewProblemDetailsResponseWriter from ProvidedServices of any module
+type ProblemDetailsResponseWriter struct {
+	*DefaultJsonResponseWriter
+	baseTypeUri string
+	mu          sync.RWMutex
+	types       map[ErrorIdentifier]string
+}
+
+func NewProblemDetailsResponseWriter(logger Logger, config *Config, codecs *CodecRegistry) JsonResponseWriter {
+	return &ProblemDetailsResponseWriter{
+		DefaultJsonResponseWriter: &DefaultJsonResponseWriter{logger: logger, config: config, codecs: codecs},
+		baseTypeUri:               strings.TrimSuffix(config.ProblemTypeBaseUri(), "/"),
+		types:                     make(map[ErrorIdentifier]string),
+	}
+}
+
+// RegisterType sets the problem type URI of the error identifier
+func (p *ProblemDetailsResponseWriter) RegisterType(identifier ErrorIdentifier, uri string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.types[identifier] = uri
+}
+
+// TypeUri returns the problem type URI of the error identifier. Unregistered identifiers are appended
+// to the PROBLEM_TYPE_BASE_URI value, or "about:blank" is returned if it is not set
+func (p *ProblemDetailsResponseWriter) TypeUri(identifier ErrorIdentifier) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if uri, ok := p.types[identifier]; ok {
+		return uri
+	}
+	if p.baseTypeUri == "" || identifier == "" {
+		return "about:blank"
+	}
+	return p.baseTypeUri + "/" + string(identifier)
+}
+
+func (p *ProblemDetailsResponseWriter) Error(w http.ResponseWriter, r *http.Request, response ActionResponse) {
+	problem := ProblemDetails{
+		Type:     p.TypeUri(UnknownError),
+		Title:    http.StatusText(response.StatusCode),
+		Status:   response.StatusCode,
+		Detail:   "Unknown error",
+		Instance: r.URL.RequestURI(),
+	}
+	if response.Error != nil {
+		problem.Type = p.TypeUri(response.Error.Identifier)
+		problem.Detail = response.Error.Error()
+		problem.Code = response.Error.Identifier
+		problem.Details = response.Error.Details
+		problem.RetryAfter = setRetryAfter(w, response.Error.RetryAfter)
+		for _, validationError := range response.Error.ValidationErrors {
+			problem.Errors = append(problem.Errors, InvalidInputBody{
+				Id:      string(validationError.Identifier),
+				Field:   validationError.Field,
+				Message: validationError.Err,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", ProblemJsonMediaType)
+	jsonResp, err := json.Marshal(problem)
+	if err != nil {
+		ctx := r.Context()
+		p.logger.Error(ctx, "Error happened in JSON marshal. Err: %s", err)
+		w.WriteHeader(response.StatusCode)
+		_, _ = w.Write([]byte(`{"type": "about:blank", "title": "Error happened in JSON marshal."}`))
+		return
+	}
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write(jsonResp)
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ProblemWriterSp struct {
+}
+
+func (p *ProblemWriterSp) ProvidedServices() []interface{} {
+	return []interface{}{NewProblemDetailsResponseWriter}
+}
+
+func TestProblemDetailsResponseWriterIsSelectable(t *testing.T) {
+	app := New([]interface{}{&ProblemWriterSp{}})
+	app.setDefaultLogger()
+	app.setDefaultJsonResponseWriter()
+
+	var writer JsonResponseWriter
+	err := app.Container().Invoke(func(dep JsonResponseWriter) {
+		writer = dep
+	})
+	assert.Nil(t, err)
+	assert.IsType(t, &ProblemDetailsResponseWriter{}, writer)
+}
+
+func TestProblemDetailsResponseWriterError(t *testing.T) {
+	t.Setenv("PROBLEM_TYPE_BASE_URI", "https://example.com/problems/")
+	writer := NewProblemDetailsResponseWriter(NewDefaultLogger(), NewConfig(), NewCodecRegistry())
+	writer.(*ProblemDetailsResponseWriter).RegisterType(InvalidRequest, "https://example.com/invalid")
+	ctx := context.Background()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users?ref=1", nil)
+	writer.Error(w, r, NewValidationErrorResponse(ctx, []ValidationError{
+		*NewValidationError("name", "Name is required", "required"),
+	}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemJsonMediaType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "https://example.com/invalid",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "Name is required",
+		"instance": "/users?ref=1",
+		"code": "InvalidRequest",
+		"errors": [{"id": "required", "field": "name", "message": "Name is required"}]
+	}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	writer.Error(w, r, NewUnprocessableEntityResponse(ctx, NewCommonError("UserExists", "User exists")))
+	assert.Contains(t, w.Body.String(), `"type":"https://example.com/problems/UserExists"`)
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recoverer converts panics of http handlers to 500 error responses written by the JsonResponseWriter.
+// The application wraps all routes with its middleware
+type Recoverer struct {
+	logger      Logger
+	writer      JsonResponseWriter
+	exposeStack bool
+}
+
+// NewRecoverer reads the APP_EXPOSE_STACK_TRACES setting once, so a malformed value fails the application start
+func NewRecoverer(logger Logger, writer JsonResponseWriter, config *Config) (*Recoverer, error) {
+	exposeStack, err := config.ExposeStackTraces()
+	if err != nil {
+		return nil, err
+	}
+	return &Recoverer{
+		logger:      logger,
+		writer:      writer,
+		exposeStack: exposeStack && !config.AppEnvIsProd(),
+	}, nil
+}
+
+// Middleware recovers panics of the next handler
+func (rec *Recoverer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracker := &writeTracker{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			stack := string(debug.Stack())
+			ctx := r.Context()
+			rec.logger.Error(ctx, fmt.Sprintf("panic in %s %s: %v\n%s", r.Method, r.URL.Path, recovered, stack))
+			if tracker.written {
+				return
+			}
+			rec.writer.Error(w, r, rec.response(ctx, recovered, stack))
+		}()
+		next.ServeHTTP(tracker, r)
+	})
+}
+
+func (rec *Recoverer) response(ctx context.Context, recovered any, stack string) ActionResponse {
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("%v", recovered)
+	}
+	response := NewServerErrorResponse(ctx, UnknownError, errors.New("Internal server error"))
+	if rec.exposeStack {
+		response = NewServerErrorResponse(ctx, UnknownError, err).
+			WithDetails(map[string]any{"stack": stack})
+	}
+	return response
+}
+
+// writeTracker remembers if the response has been started
+type writeTracker struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (t *writeTracker) WriteHeader(statusCode int) {
+	t.written = true
+	t.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (t *writeTracker) Write(b []byte) (int, error) {
+	t.written = true
+	return t.ResponseWriter.Write(b)
+}
+
+// Flush allows streaming handlers to use http.Flusher through the middleware
+func (t *writeTracker) Flush() {
+	t.written = true
+	if flusher, ok := t.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack allows websocket handlers to use http.Hijacker through the middleware
+func (t *writeTracker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := t.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	t.written = true
+	return hijacker.Hijack()
+}
+
+func (t *writeTracker) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
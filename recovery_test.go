@@ -0,0 +1,95 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type testLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) log(level string, s string, i ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, level+": "+fmt.Sprint(append([]interface{}{s}, i...)...))
+}
+
+func (l *testLogger) Debug(ctx context.Context, s string, i ...interface{}) { l.log("DEBUG", s, i...) }
+func (l *testLogger) Info(ctx context.Context, s string, i ...interface{})  { l.log("INFO", s, i...) }
+func (l *testLogger) Warn(ctx context.Context, s string, i ...interface{})  { l.log("WARN", s, i...) }
+func (l *testLogger) Error(ctx context.Context, s string, i ...interface{}) { l.log("ERROR", s, i...) }
+func (l *testLogger) Panic(ctx context.Context, s string, i ...interface{}) { l.log("PANIC", s, i...) }
+
+func panickingHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map")
+	})
+}
+
+func TestRecovererMiddleware(t *testing.T) {
+	t.Setenv("APP_ENV", ProdEnv)
+	t.Setenv("APP_EXPOSE_STACK_TRACES", "true")
+	logger := &testLogger{}
+	writer := NewJsonResponseWriter(logger, NewConfig(), NewCodecRegistry())
+	recoverer, err := NewRecoverer(logger, writer, NewConfig())
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	recoverer.Middleware(panickingHandler()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error":"Internal server error"}`, w.Body.String())
+	assert.Len(t, logger.messages, 1)
+	assert.Contains(t, logger.messages[0], "ERROR: panic in GET /users: nil map")
+	assert.Contains(t, logger.messages[0], "runtime/debug.Stack")
+}
+
+func TestRecovererMiddlewareExposesStack(t *testing.T) {
+	t.Setenv("APP_ENV", DevEnv)
+	t.Setenv("APP_EXPOSE_STACK_TRACES", "true")
+	logger := &testLogger{}
+	writer := NewJsonResponseWriter(logger, NewConfig(), NewCodecRegistry())
+	recoverer, err := NewRecoverer(logger, writer, NewConfig())
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	recoverer.Middleware(panickingHandler()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"nil map"`)
+	assert.Contains(t, w.Body.String(), `"stack":"goroutine`)
+}
+
+func TestRecovererKeepsFlusher(t *testing.T) {
+	logger := &testLogger{}
+	recoverer, err := NewRecoverer(logger, NewJsonResponseWriter(logger, NewConfig(), NewCodecRegistry()), NewConfig())
+	assert.Nil(t, err)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		assert.True(t, ok)
+		_, _ = w.Write([]byte("chunk"))
+		flusher.Flush()
+	})
+
+	w := httptest.NewRecorder()
+	recoverer.Middleware(handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "chunk", w.Body.String())
+}
+
+func TestRecovererRejectsMalformedSetting(t *testing.T) {
+	t.Setenv("APP_EXPOSE_STACK_TRACES", "sometimes")
+	logger := &testLogger{}
+
+	_, err := NewRecoverer(logger, NewJsonResponseWriter(logger, NewConfig(), NewCodecRegistry()), NewConfig())
+
+	assert.Equal(t, ConfigKeyError{Key: "APP_EXPOSE_STACK_TRACES", Err: "should be boolean"}, err)
+}
@@ -1,8 +1,14 @@
 package application
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"time"
 )
 
 type Router interface {
@@ -12,17 +18,44 @@ type Router interface {
 }
 
 type RouteInfo struct {
-	method  string
-	path    string
-	handler http.HandlerFunc
+	method      string
+	path        string
+	handler     http.HandlerFunc
+	middlewares []Middleware
+	doc         *RouteDoc
 }
 
-func NewRouteInfo(method string, path string, handler http.HandlerFunc) *RouteInfo {
-	return &RouteInfo{method: method, path: path, handler: handler}
+func NewRouteInfo(method string, path string, handler http.HandlerFunc, middlewares ...Middleware) *RouteInfo {
+	return &RouteInfo{method: method, path: path, handler: handler, middlewares: middlewares}
 }
 
+// Handler returns the handler of the route wrapped with the route middlewares
 func (r RouteInfo) Handler() http.HandlerFunc {
-	return r.handler
+	if len(r.middlewares) == 0 {
+		return r.handler
+	}
+	return Chain(r.handler, r.middlewares...).ServeHTTP
+}
+
+// WithPrefix returns a copy of the route with the path prefixed by the prefix
+func (r RouteInfo) WithPrefix(prefix string) RouteInfo {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return r
+	}
+	r.path = prefix + r.path
+	return r
+}
+
+// WithMiddlewares returns a copy of the route wrapped with the middlewares outside the route's own middlewares
+func (r RouteInfo) WithMiddlewares(middlewares ...Middleware) RouteInfo {
+	if len(middlewares) == 0 {
+		return r
+	}
+	result := make([]Middleware, 0, len(middlewares)+len(r.middlewares))
+	result = append(result, middlewares...)
+	r.middlewares = append(result, r.middlewares...)
+	return r
 }
 
 func (r RouteInfo) Method() string {
@@ -32,3 +65,113 @@ func (r RouteInfo) Method() string {
 func (r RouteInfo) Path() string {
 	return r.path
 }
+
+// Doc returns the documentation of the route or nil if the route is neither described nor added by Routes.Handle
+func (r RouteInfo) Doc() *RouteDoc {
+	return r.doc
+}
+
+// Describe sets the documentation of the route used for the OpenAPI document generation.
+// The request and responses recorded by HandleDocumented are kept unless the doc overrides them
+func (r *RouteInfo) Describe(doc RouteDoc) *RouteInfo {
+	if r.doc != nil {
+		if doc.Request == nil {
+			doc.Request = r.doc.Request
+		}
+		if doc.Responses == nil {
+			doc.Responses = r.doc.Responses
+		}
+	}
+	r.doc = &doc
+	return r
+}
+
+var pathWildcardRegexp = regexp.MustCompile(`{([^}.$]+)(\.\.\.)?}`)
+
+type routeParamsKey struct{}
+
+// DefaultRouter is a Router built on http.ServeMux. Paths of routes may contain wildcards
+// supported by http.ServeMux, for example "/users/{id}" or "/files/{path...}"
+type DefaultRouter struct {
+	mux             *http.ServeMux
+	addr            string
+	shutdownTimeout time.Duration
+	routesCount     int
+}
+
+func NewDefaultRouter(config *Config) Router {
+	return &DefaultRouter{
+		mux:             http.NewServeMux(),
+		addr:            config.HttpAddr(),
+		shutdownTimeout: config.ShutdownTimeout(),
+	}
+}
+
+func (d *DefaultRouter) AddRoutes(routes []RouteInfo) {
+	for _, route := range routes {
+		names := make([]string, 0)
+		for _, match := range pathWildcardRegexp.FindAllStringSubmatch(route.path, -1) {
+			names = append(names, match[1])
+		}
+		handler := route.Handler()
+		d.mux.HandleFunc(route.method+" "+route.path, func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), routeParamsKey{}, names)
+			handler(w, r.WithContext(ctx))
+		})
+		d.routesCount++
+	}
+}
+
+// Run listens the address from the HTTP_ADDR variable until the server fails
+func (d *DefaultRouter) Run() error {
+	return d.Serve(context.Background())
+}
+
+// Serve listens the address from the HTTP_ADDR variable until ctx is cancelled,
+// then gracefully shuts down the server within ShutdownContext
+func (d *DefaultRouter) Serve(ctx context.Context) error {
+	server := &http.Server{
+		Addr:    d.addr,
+		Handler: d.mux,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ShutdownContext(ctx), d.shutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(shutdownCtx)
+	if listenErr := <-serverErr; !errors.Is(listenErr, http.ErrServerClosed) {
+		return listenErr
+	}
+	return err
+}
+
+func (d *DefaultRouter) RouteParams(r *http.Request) url.Values {
+	values := url.Values{}
+	names, _ := r.Context().Value(routeParamsKey{}).([]string)
+	for _, name := range names {
+		values.Set(name, r.PathValue(name))
+	}
+	return values
+}
+
+// ServeHTTP allows using the router as http.Handler, for example in tests
+func (d *DefaultRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mux.ServeHTTP(w, r)
+}
+
+// HasRoutes returns true if any route was added to the router
+func (d *DefaultRouter) HasRoutes() bool {
+	return d.routesCount > 0
+}
@@ -0,0 +1,83 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDefaultRouterRouteParams(t *testing.T) {
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	var params url.Values
+	routes := NewRoutes()
+	routes.Get("/users/{id}/files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		params = router.RouteParams(r)
+	})
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/12/files/a/b.txt", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "12", params.Get("id"))
+	assert.Equal(t, "a/b.txt", params.Get("path"))
+}
+
+func TestDefaultRouterMatchesMethod(t *testing.T) {
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	routes := NewRoutes()
+	routes.Post("/users", func(w http.ResponseWriter, r *http.Request) {})
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestDefaultRouterServesHeadByGetRoutes(t *testing.T) {
+	buf := captureLog(t)
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	var id int
+	routes := NewRoutes()
+	routes.Get("/items/{id}", Handle(runner, func(ctx context.Context, request *bindTestRequest) (int, error) {
+		id = request.Id
+		return request.Id, nil
+	}))
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/items/7", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 7, id)
+	assert.NotContains(t, buf.String(), "unsupported http method")
+}
+
+type failingRouterSp struct {
+	err error
+}
+
+func (s *failingRouterSp) ProvidedServices() []interface{} {
+	return []interface{}{
+		func() (Router, error) {
+			return nil, s.err
+		},
+	}
+}
+
+func TestRunApplicationReturnsRouterError(t *testing.T) {
+	sp := &failingRouterSp{err: errors.New("cannot create router")}
+	app := New([]interface{}{sp})
+
+	err := app.Run()
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "cannot create router")
+	}
+}
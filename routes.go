@@ -11,64 +11,83 @@ type Action interface {
 }
 
 type Routes struct {
-	routes []RouteInfo
+	prefix      string
+	routes      []*RouteInfo
+	middlewares []Middleware
+	groups      []*Routes
 }
 
 func NewRoutes() *Routes {
-	return &Routes{routes: make([]RouteInfo, 0)}
+	return &Routes{routes: make([]*RouteInfo, 0)}
 }
 
-func (r *Routes) Get(path string, handler http.HandlerFunc) {
-	r.routes = append(r.routes, RouteInfo{
-		handler: handler,
-		method:  http.MethodGet,
-		path:    path,
-	})
+// Use adds middlewares to all routes of the set
+func (r *Routes) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
 }
 
-func (r *Routes) Post(path string, handler http.HandlerFunc) {
-	r.routes = append(r.routes, RouteInfo{
-		handler: handler,
-		method:  http.MethodPost,
-		path:    path,
-	})
+func (r *Routes) Get(path string, handler http.HandlerFunc, middlewares ...Middleware) *RouteInfo {
+	return r.add(http.MethodGet, path, handler, middlewares)
 }
 
-func (r *Routes) Delete(path string, handler http.HandlerFunc) {
-	r.routes = append(r.routes, RouteInfo{
-		handler: handler,
-		method:  http.MethodDelete,
-		path:    path,
-	})
+func (r *Routes) Post(path string, handler http.HandlerFunc, middlewares ...Middleware) *RouteInfo {
+	return r.add(http.MethodPost, path, handler, middlewares)
 }
 
-func (r *Routes) Put(path string, handler http.HandlerFunc) {
-	r.routes = append(r.routes, RouteInfo{
-		handler: handler,
-		method:  http.MethodPut,
-		path:    path,
-	})
+func (r *Routes) Delete(path string, handler http.HandlerFunc, middlewares ...Middleware) *RouteInfo {
+	return r.add(http.MethodDelete, path, handler, middlewares)
 }
 
-func (r *Routes) Options(path string, handler http.HandlerFunc) {
-	r.routes = append(r.routes, RouteInfo{
-		handler: handler,
-		method:  http.MethodOptions,
-		path:    path,
-	})
+func (r *Routes) Put(path string, handler http.HandlerFunc, middlewares ...Middleware) *RouteInfo {
+	return r.add(http.MethodPut, path, handler, middlewares)
 }
 
+func (r *Routes) Options(path string, handler http.HandlerFunc, middlewares ...Middleware) *RouteInfo {
+	return r.add(http.MethodOptions, path, handler, middlewares)
+}
+
+// Handle adds the route of the handler created by HandleDocumented, so the route is documented
+// by the request and the response of the handler
+func (r *Routes) Handle(method string, path string, handler DocumentedHandler, middlewares ...Middleware) *RouteInfo {
+	info := r.add(method, path, handler.Handler, middlewares)
+	info.doc = &handler.Doc
+	return info
+}
+
+// Group returns a nested set of routes. Paths of its routes are prefixed with the prefix
+// and wrapped with the middlewares after the middlewares of the parent set
+func (r *Routes) Group(prefix string, middlewares ...Middleware) *Routes {
+	group := NewRoutes()
+	group.prefix = prefix
+	group.middlewares = middlewares
+	r.groups = append(r.groups, group)
+	return group
+}
+
+// AddFromRoutes adds all routes of the other set including its groups, prefixes and middlewares
 func (r *Routes) AddFromRoutes(routes *Routes) {
-	for name, info := range routes.routes {
-		r.routes[name] = info
+	for _, info := range routes.GetRoutesInfo() {
+		r.routes = append(r.routes, &info)
 	}
 }
 
 func (r *Routes) GetRoutesInfo() []RouteInfo {
 	result := make([]RouteInfo, 0, len(r.routes))
 	for _, info := range r.routes {
-		result = append(result, info)
+		result = append(result, *info)
+	}
+	for _, group := range r.groups {
+		result = append(result, group.GetRoutesInfo()...)
+	}
+	for i, info := range result {
+		result[i] = info.WithPrefix(r.prefix).WithMiddlewares(r.middlewares...)
 	}
 
 	return result
 }
+
+func (r *Routes) add(method string, path string, handler http.HandlerFunc, middlewares []Middleware) *RouteInfo {
+	info := NewRouteInfo(method, path, handler, middlewares...)
+	r.routes = append(r.routes, info)
+	return info
+}
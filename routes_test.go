@@ -0,0 +1,64 @@
+package application
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoutesGroup(t *testing.T) {
+	var calls []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	}
+	routes := NewRoutes()
+	routes.Use(tracingMiddleware("root", &calls))
+	routes.Get("/health", handler)
+	users := routes.Group("/users/", tracingMiddleware("users", &calls))
+	users.Get("/{id}", handler)
+	admin := users.Group("/admin", tracingMiddleware("admin", &calls))
+	admin.Delete("/{id}", handler)
+
+	infos := routes.GetRoutesInfo()
+	assert.Len(t, infos, 3)
+	assert.Equal(t, "/health", infos[0].Path())
+	assert.Equal(t, "/users/{id}", infos[1].Path())
+	assert.Equal(t, "/users/admin/{id}", infos[2].Path())
+	assert.Equal(t, http.MethodDelete, infos[2].Method())
+
+	infos[2].Handler()(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/users/admin/1", nil))
+	assert.Equal(t, []string{"root", "users", "admin", "handler"}, calls)
+}
+
+func TestRoutesAddFromRoutes(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	routes := NewRoutes()
+	routes.Get("/a", handler)
+	other := NewRoutes()
+	other.Get("/b", handler)
+	other.Group("/c").Post("/d", handler)
+
+	routes.AddFromRoutes(other)
+
+	infos := routes.GetRoutesInfo()
+	assert.Len(t, infos, 3)
+	assert.Equal(t, "/c/d", infos[2].Path())
+}
+
+func TestApplicationMountModule(t *testing.T) {
+	var calls []string
+	module := &MiddlewareSp{calls: &calls}
+	app := New([]interface{}{module})
+	app.MountModule(module, "/api/v1/billing")
+	app.setDefaults()
+
+	err := app.initHttpRoutes()
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	router := app.getRouter().(*DefaultRouter)
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/billing/ping", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, calls, "handler")
+}
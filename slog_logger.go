@@ -0,0 +1,101 @@
+package application
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// LevelPanic is the level of messages logged by SlogLogger.Panic
+const LevelPanic = slog.Level(12)
+
+// SlogLogger is a Logger backed by log/slog. Arguments of its methods may be slog.Attr values
+// or key-value pairs, for example
+//
+//	logger.Info(ctx, "user registered", slog.Int("userId", id), "email", email)
+//
+// Log fields attached to the context by WithLogFields are added to each message.
+// If the message contains formatting verbs, as many arguments that are not slog.Attr values format the message
+// the way fmt.Sprintf does, so printf-style calls keep working.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a logger writing to stderr with the handler and the level of the config.
+// To use it instead of the default logger return it from ProvidedServices of any module
+func NewSlogLogger(config *Config) (Logger, error) {
+	level, err := config.LogLevel()
+	if err != nil {
+		return nil, err
+	}
+	return NewSlogLoggerWithHandler(NewSlogHandler(os.Stderr, config.LogFormat(), level)), nil
+}
+
+func NewSlogLoggerWithHandler(handler slog.Handler) Logger {
+	return &SlogLogger{logger: slog.New(handler)}
+}
+
+// NewSlogHandler creates a json or text handler
+func NewSlogHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	options := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.LevelKey && len(groups) == 0 {
+				if level, ok := attr.Value.Any().(slog.Level); ok && level == LevelPanic {
+					return slog.String(slog.LevelKey, "PANIC")
+				}
+			}
+			return attr
+		},
+	}
+	if format == "json" {
+		return slog.NewJSONHandler(w, options)
+	}
+	return slog.NewTextHandler(w, options)
+}
+
+// Slog returns the underlying slog logger
+func (l *SlogLogger) Slog() *slog.Logger {
+	return l.logger
+}
+
+func (l *SlogLogger) Debug(ctx context.Context, s string, i ...interface{}) {
+	l.log(ctx, slog.LevelDebug, s, i)
+}
+
+func (l *SlogLogger) Info(ctx context.Context, s string, i ...interface{}) {
+	l.log(ctx, slog.LevelInfo, s, i)
+}
+
+func (l *SlogLogger) Warn(ctx context.Context, s string, i ...interface{}) {
+	l.log(ctx, slog.LevelWarn, s, i)
+}
+
+func (l *SlogLogger) Error(ctx context.Context, s string, i ...interface{}) {
+	l.log(ctx, slog.LevelError, s, i)
+}
+
+// Panic logs the message and panics with it
+func (l *SlogLogger) Panic(ctx context.Context, s string, i ...interface{}) {
+	message := l.log(ctx, LevelPanic, s, i)
+	panic(message)
+}
+
+func (l *SlogLogger) log(ctx context.Context, level slog.Level, s string, i []interface{}) string {
+	message, args := formatLogArgs(s, i, isSlogFormatArg)
+	if fields := LogFields(ctx); len(fields) > 0 {
+		contextArgs := make([]any, 0, len(fields)+len(args))
+		for _, field := range fields {
+			contextArgs = append(contextArgs, field)
+		}
+		args = append(contextArgs, args...)
+	}
+	l.logger.Log(ctx, level, message, args...)
+	return message
+}
+
+func isSlogFormatArg(arg interface{}) bool {
+	_, isAttr := arg.(slog.Attr)
+	return !isAttr
+}
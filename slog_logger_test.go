@@ -0,0 +1,134 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogLoggerAttributes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewSlogLoggerWithHandler(NewSlogHandler(buf, "json", slog.LevelInfo))
+
+	logger.Info(context.Background(), "user registered", slog.Int("userId", 5), "email", "a@b.c")
+
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "INFO", line["level"])
+	assert.Equal(t, "user registered", line["msg"])
+	assert.Equal(t, float64(5), line["userId"])
+	assert.Equal(t, "a@b.c", line["email"])
+}
+
+func TestSlogLoggerFormatsMessage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewSlogLoggerWithHandler(NewSlogHandler(buf, "json", slog.LevelInfo))
+
+	logger.Error(context.Background(), "Cannot send email to %s", "a@b.c", slog.Int("userId", 5), "attempt", 2)
+
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "ERROR", line["level"])
+	assert.Equal(t, "Cannot send email to a@b.c", line["msg"])
+	assert.Equal(t, float64(5), line["userId"])
+	assert.Equal(t, float64(2), line["attempt"])
+}
+
+func TestSlogLoggerKeepsPercentWithoutVerbs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewSlogLoggerWithHandler(NewSlogHandler(buf, "json", slog.LevelInfo))
+
+	logger.Warn(context.Background(), "disk is 90% full", "path", "/var")
+
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "disk is 90% full", line["msg"])
+	assert.Equal(t, "/var", line["path"])
+}
+
+func TestSlogLoggerFiltersLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewSlogLoggerWithHandler(NewSlogHandler(buf, "json", slog.LevelWarn))
+
+	logger.Info(context.Background(), "skipped")
+	logger.Debug(context.Background(), "skipped")
+
+	assert.Empty(t, buf.String())
+}
+
+func TestSlogLoggerPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewSlogLoggerWithHandler(NewSlogHandler(buf, "json", slog.LevelInfo))
+
+	assert.PanicsWithValue(t, "broken 1", func() {
+		logger.Panic(context.Background(), "broken %d", 1)
+	})
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "PANIC", line["level"])
+}
+
+func TestSlogLoggerTextHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewSlogLoggerWithHandler(NewSlogHandler(buf, "text", slog.LevelInfo))
+
+	logger.Warn(context.Background(), "slow query", slog.Duration("took", 0))
+
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), `msg="slow query"`)
+	assert.Contains(t, buf.String(), "took=0s")
+}
+
+func TestConfigLogLevel(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	config := NewConfig()
+
+	level, err := config.LogLevel()
+	assert.Nil(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	t.Setenv("LOG_LEVEL", "verbose")
+	_, err = config.LogLevel()
+	assert.Equal(t, ConfigKeyError{Key: "LOG_LEVEL", Err: "should be one of debug, info, warn, error"}, err)
+
+	_, err = NewSlogLogger(config)
+	assert.NotNil(t, err)
+}
+
+func TestConfigDefaultLogLevel(t *testing.T) {
+	config := NewConfig(MapSource{})
+
+	level, err := config.LogLevel()
+	assert.Nil(t, err)
+	assert.Equal(t, slog.LevelInfo, level)
+}
+
+func TestRunApplicationReturnsLogLevelError(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "verbose")
+	app := New([]interface{}{&SlogLoggerSp{}})
+	go app.Shutdown(nil)
+
+	err := app.Run()
+
+	assert.Equal(t, ConfigKeyError{Key: "LOG_LEVEL", Err: "should be one of debug, info, warn, error"}, err)
+}
+
+func TestConfigLogFormat(t *testing.T) {
+	config := NewConfig(MapSource{})
+	assert.Equal(t, "text", config.LogFormat())
+
+	t.Setenv("LOG_FORMAT", "JSON")
+	assert.Equal(t, "json", NewConfig().LogFormat())
+}
+
+type SlogLoggerSp struct {
+}
+
+func (s *SlogLoggerSp) ProvidedServices() []interface{} {
+	return []interface{}{
+		NewSlogLogger,
+	}
+}
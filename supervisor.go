@@ -0,0 +1,252 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// RunnableServicesProvider if service provider implements this method its services will be run
+// concurrently by the Supervisor after starting of all modules
+type RunnableServicesProvider interface {
+	// RunnableServices returns long-running services of the module, such as a web-server or a queue consumer
+	RunnableServices() []*RunnableService
+}
+
+// RestartPolicy describes how the supervisor restarts a failed service.
+// A service is restarted MaxRestarts times, waiting Backoff before the first restart
+// and doubling the waiting time up to MaxBackoff for each next one.
+// A service that has run ResetAfter before failing is treated as healthy,
+// so the count of its restarts and the waiting time start over.
+type RestartPolicy struct {
+	// MaxRestarts is the number of restarts, a negative value means unlimited restarts
+	MaxRestarts int
+	// Backoff is the waiting time before the first restart, DefaultRestartBackoff is used if it is not positive
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// ResetAfter is the running time of a healthy service, DefaultRestartResetAfter is used if it is not positive
+	ResetAfter time.Duration
+}
+
+const (
+	DefaultRestartBackoff    = 100 * time.Millisecond
+	DefaultRestartResetAfter = time.Minute
+)
+
+func NoRestart() RestartPolicy {
+	return RestartPolicy{}
+}
+
+func AlwaysRestart(backoff time.Duration, maxBackoff time.Duration) RestartPolicy {
+	return RestartPolicy{MaxRestarts: -1, Backoff: backoff, MaxBackoff: maxBackoff}
+}
+
+type RunnableService struct {
+	name          string
+	module        string
+	run           func(ctx context.Context) error
+	restartPolicy RestartPolicy
+}
+
+// NewRunnableService creates a service. The run function should block until ctx is cancelled.
+// A returned error stops the application unless the restart policy allows restarting the service.
+func NewRunnableService(name string, run func(ctx context.Context) error) *RunnableService {
+	return &RunnableService{name: name, run: run, restartPolicy: NoRestart()}
+}
+
+func (s *RunnableService) WithRestartPolicy(policy RestartPolicy) *RunnableService {
+	s.restartPolicy = policy
+	return s
+}
+
+func (s *RunnableService) Name() string {
+	return s.name
+}
+
+// Supervisor runs long-running services concurrently.
+// The first fatal error of any service cancels all others.
+type Supervisor struct {
+	logger          Logger
+	shutdownTimeout time.Duration
+
+	mu       sync.Mutex
+	services []*RunnableService
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	errOnce  sync.Once
+	err      error
+
+	stopOnce sync.Once
+	stopping chan struct{}
+	drainCtx context.Context
+}
+
+type supervisorKey struct{}
+
+// ShutdownContext returns the context a service should use to finish its work after its run context is cancelled,
+// for example to drain connections. It is done when the part of the shutdown timeout given to services is exceeded,
+// so close listeners of modules still have time to release resources.
+// Outside the supervisor it returns context.Background()
+func ShutdownContext(ctx context.Context) context.Context {
+	s, ok := ctx.Value(supervisorKey{}).(*Supervisor)
+	if !ok {
+		return context.Background()
+	}
+	<-s.stopping
+	return s.drainCtx
+}
+
+func NewSupervisor(logger Logger, config *Config) *Supervisor {
+	return &Supervisor{logger: logger, shutdownTimeout: config.ShutdownTimeout()}
+}
+
+// Add registers a service. If the supervisor is already running the service is started immediately
+func (s *Supervisor) Add(service *RunnableService) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.services = append(s.services, service)
+	if s.ctx != nil {
+		s.start(service)
+	}
+}
+
+// Run starts all registered services and blocks until ctx is cancelled or any service fails.
+// Then it cancels all services and waits for them within a half of the shutdown timeout.
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.Serve(ctx)
+	stopCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	return s.Stop(stopCtx)
+}
+
+// Serve starts all registered services and blocks until ctx is cancelled or any service fails.
+// Services keep running until Stop is called
+func (s *Supervisor) Serve(ctx context.Context) {
+	s.mu.Lock()
+	if s.stopping == nil {
+		s.stopping = make(chan struct{})
+	}
+	s.ctx, s.cancel = context.WithCancel(context.WithValue(ctx, supervisorKey{}, s))
+	for _, service := range s.services {
+		s.start(service)
+	}
+	s.mu.Unlock()
+
+	<-s.ctx.Done()
+}
+
+// Stop cancels all services and waits for them for a half of the time left until ctx is done,
+// the rest of the time is left to close listeners of modules.
+// It returns the first error of services or ErrShutdownTimeout
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	drainCtx, cancelDrain := drainContext(ctx)
+	defer cancelDrain()
+	s.stopOnce.Do(func() {
+		s.drainCtx = drainCtx
+		close(s.stopping)
+	})
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-drainCtx.Done():
+		s.fail(ErrShutdownTimeout)
+	}
+
+	return s.err
+}
+
+// drainContext takes a half of the time left until the deadline of ctx
+func drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-time.Until(deadline)/2))
+}
+
+func (s *Supervisor) start(service *RunnableService) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := s.supervise(s.ctx, service)
+		if err != nil {
+			s.fail(s.serviceError(service, err))
+		}
+	}()
+}
+
+func (s *Supervisor) supervise(ctx context.Context, service *RunnableService) error {
+	policy := service.restartPolicy
+	if policy.Backoff <= 0 {
+		policy.Backoff = DefaultRestartBackoff
+	}
+	if policy.ResetAfter <= 0 {
+		policy.ResetAfter = DefaultRestartResetAfter
+	}
+	backoff := policy.Backoff
+	for restarts := 0; ; restarts++ {
+		started := time.Now()
+		err := s.runOnce(ctx, service)
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(started) >= policy.ResetAfter {
+			restarts = 0
+			backoff = policy.Backoff
+		}
+		if policy.MaxRestarts >= 0 && restarts >= policy.MaxRestarts {
+			return err
+		}
+		s.logger.Warn(
+			ctx,
+			fmt.Sprintf("service %s failed, restarting in %s: %s", service.name, backoff, err.Error()),
+		)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = max(policy.MaxBackoff, policy.Backoff)
+		}
+	}
+}
+
+func (s *Supervisor) runOnce(ctx context.Context, service *RunnableService) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return service.run(ctx)
+}
+
+func (s *Supervisor) fail(err error) {
+	s.errOnce.Do(func() {
+		s.err = err
+	})
+	s.cancel()
+}
+
+func (s *Supervisor) serviceError(service *RunnableService, err error) error {
+	if service.module == "" {
+		return fmt.Errorf("service %s failed: %w", service.name, err)
+	}
+	return &ModuleError{Module: service.module, Stage: "service " + service.name, Err: err}
+}
@@ -0,0 +1,197 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSupervisorFatalErrorCancelsOtherServices(t *testing.T) {
+	fatalErr := errors.New("consumer failed")
+	cancelled := make(chan struct{})
+	supervisor := &Supervisor{logger: NewDefaultLogger(), shutdownTimeout: time.Second}
+	supervisor.Add(NewRunnableService("server", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return nil
+	}))
+	supervisor.Add(NewRunnableService("consumer", func(ctx context.Context) error {
+		return fatalErr
+	}))
+
+	err := supervisor.Run(context.Background())
+	assert.ErrorIs(t, err, fatalErr)
+	<-cancelled
+}
+
+func TestSupervisorRestartsService(t *testing.T) {
+	attempts := 0
+	supervisor := &Supervisor{logger: NewDefaultLogger(), shutdownTimeout: time.Second}
+	supervisor.Add(
+		NewRunnableService("cron", func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				panic("crashed")
+			}
+			return errors.New("crashed again")
+		}).WithRestartPolicy(RestartPolicy{MaxRestarts: 2, Backoff: time.Millisecond}),
+	)
+
+	err := supervisor.Run(context.Background())
+	assert.EqualError(t, err, "service cron failed: crashed again")
+	assert.Equal(t, 3, attempts)
+}
+
+func TestSupervisorWaitsDefaultBackoffForZeroBackoff(t *testing.T) {
+	captureLog(t)
+	attempts := 0
+	supervisor := &Supervisor{logger: NewDefaultLogger(), shutdownTimeout: time.Second}
+	supervisor.Add(
+		NewRunnableService("cron", func(ctx context.Context) error {
+			attempts++
+			return errors.New("crashed")
+		}).WithRestartPolicy(AlwaysRestart(0, 0)),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRestartBackoff/2)
+	defer cancel()
+
+	err := supervisor.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestSupervisorResetsRestartsAfterHealthyRun(t *testing.T) {
+	captureLog(t)
+	attempts := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	supervisor := &Supervisor{logger: NewDefaultLogger(), shutdownTimeout: time.Second}
+	supervisor.Add(
+		NewRunnableService("cron", func(ctx context.Context) error {
+			attempts++
+			switch attempts {
+			case 2:
+				time.Sleep(20 * time.Millisecond)
+			case 4:
+				cancel()
+				return nil
+			}
+			return errors.New("crashed")
+		}).WithRestartPolicy(RestartPolicy{MaxRestarts: 2, Backoff: time.Millisecond, ResetAfter: 10 * time.Millisecond}),
+	)
+
+	err := supervisor.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, attempts)
+}
+
+func TestRunApplicationStopsOnServiceError(t *testing.T) {
+	serviceErr := errors.New("cannot listen")
+	app := New([]interface{}{&ServiceSp{err: serviceErr}})
+	err := app.Run()
+	assert.ErrorIs(t, err, serviceErr)
+	var moduleErr *ModuleError
+	assert.ErrorAs(t, err, &moduleErr)
+	assert.Equal(t, "service server", moduleErr.Stage)
+}
+
+type ServiceSp struct {
+	err error
+}
+
+func (s *ServiceSp) RunnableServices() []*RunnableService {
+	return []*RunnableService{
+		NewRunnableService("server", func(ctx context.Context) error {
+			return s.err
+		}),
+	}
+}
+
+func TestRunApplicationSharesShutdownTimeout(t *testing.T) {
+	t.Setenv("APP_SHUTDOWN_TIMEOUT", "100ms")
+	closer := &DeadlineCloseSp{deadline: make(chan time.Time, 1)}
+	service := &SlowServiceSp{drainDeadline: make(chan time.Time, 1)}
+	app := New([]interface{}{closer, service})
+	go app.Shutdown(nil)
+
+	err := app.Run()
+
+	assert.ErrorIs(t, err, ErrShutdownTimeout)
+	drainDeadline := <-service.drainDeadline
+	closeDeadline := <-closer.deadline
+	// with separate timeouts close listeners would get the whole timeout after services are stopped
+	assert.True(t, closeDeadline.After(drainDeadline))
+	assert.LessOrEqual(t, closeDeadline.Sub(drainDeadline), 50*time.Millisecond)
+}
+
+// SlowServiceSp ignores its drain deadline, so the supervisor stops waiting for it by the timeout
+type SlowServiceSp struct {
+	drainDeadline chan time.Time
+}
+
+func (s *SlowServiceSp) RunnableServices() []*RunnableService {
+	return []*RunnableService{
+		NewRunnableService("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			deadline, _ := ShutdownContext(ctx).Deadline()
+			s.drainDeadline <- deadline
+			time.Sleep(time.Second)
+			return nil
+		}),
+	}
+}
+
+type DeadlineCloseSp struct {
+	deadline chan time.Time
+}
+
+func (s *DeadlineCloseSp) OnClose(ctx context.Context) error {
+	deadline, _ := ctx.Deadline()
+	s.deadline <- deadline
+	return nil
+}
+
+func TestRunApplicationLeavesTimeForCloseAfterSlowDrain(t *testing.T) {
+	t.Setenv("APP_SHUTDOWN_TIMEOUT", "400ms")
+	closer := &ClosingSp{}
+	app := New([]interface{}{closer, &DrainingServiceSp{}})
+	go app.Shutdown(nil)
+
+	err := app.Run()
+
+	assert.ErrorIs(t, err, ErrShutdownTimeout)
+	assert.True(t, closer.closed)
+	assert.Nil(t, closer.ctxErr)
+}
+
+// DrainingServiceSp drains as long as the shutdown context allows, like a server with hanging requests
+type DrainingServiceSp struct {
+}
+
+func (s *DrainingServiceSp) RunnableServices() []*RunnableService {
+	return []*RunnableService{
+		NewRunnableService("draining", func(ctx context.Context) error {
+			<-ctx.Done()
+			<-ShutdownContext(ctx).Done()
+			return nil
+		}),
+	}
+}
+
+type ClosingSp struct {
+	closed bool
+	ctxErr error
+}
+
+func (s *ClosingSp) OnClose(ctx context.Context) error {
+	time.Sleep(50 * time.Millisecond)
+	s.closed = true
+	s.ctxErr = ctx.Err()
+	return nil
+}
+
+func TestShutdownContextOutsideSupervisor(t *testing.T) {
+	assert.Equal(t, context.Background(), ShutdownContext(context.Background()))
+}
@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+)
+
+// DefaultUploadMaxMemory is the size of a multipart form kept in memory, the rest is stored in temporary files
+const DefaultUploadMaxMemory int64 = 32 << 20
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+var fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+
+type uploadLimitsKey struct{}
+
+type uploadLimits struct {
+	maxBodySize int64
+	maxMemory   int64
+}
+
+// UploadLimit is a middleware limiting the request body of a route by maxBodySize bytes.
+// Parts of a multipart form above maxMemory bytes are stored in temporary files.
+// Zero values mean no body limit and DefaultUploadMaxMemory
+func UploadLimit(maxBodySize int64, maxMemory int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxBodySize > 0 {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+			}
+			ctx := context.WithValue(r.Context(), uploadLimitsKey{}, uploadLimits{
+				maxBodySize: maxBodySize,
+				maxMemory:   maxMemory,
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestMediaType returns the media type of the request body without parameters
+func requestMediaType(r *http.Request) string {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
+
+func (j *ActionRunner) fillRequestFromForm(
+	w http.ResponseWriter,
+	r *http.Request,
+	request any,
+) error {
+	if err := r.ParseForm(); err != nil {
+		j.jsonWriter.Error(w, r, j.parseFormError(r.Context(), err))
+		return err
+	}
+	return j.fillRequestFromUrlValues(w, r, request, r.PostForm)
+}
+
+// fillRequestFromMultipartForm fills text fields of the request by qs tags
+// and fields of *multipart.FileHeader or []*multipart.FileHeader types by file tags, for example
+//
+//	Avatar *multipart.FileHeader `qs:"-" file:"avatar"`
+func (j *ActionRunner) fillRequestFromMultipartForm(
+	w http.ResponseWriter,
+	r *http.Request,
+	request any,
+) error {
+	maxMemory := DefaultUploadMaxMemory
+	if limits, ok := r.Context().Value(uploadLimitsKey{}).(uploadLimits); ok && limits.maxMemory > 0 {
+		maxMemory = limits.maxMemory
+	}
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		j.jsonWriter.Error(w, r, j.parseFormError(r.Context(), err))
+		return err
+	}
+	err := j.fillRequestFromUrlValues(w, r, request, r.MultipartForm.Value)
+	if err != nil {
+		return err
+	}
+	fillRequestFiles(request, r.MultipartForm.File)
+	return nil
+}
+
+// removeMultipartFiles removes temporary files of the parsed multipart form.
+// Routers pass a copy of the request to handlers, so the http server does not remove them
+func removeMultipartFiles(r *http.Request) {
+	if r.MultipartForm != nil {
+		_ = r.MultipartForm.RemoveAll()
+	}
+}
+
+// parseFormError converts errors of reading the request body, exceeding the body limit results in 413 response
+func (j *ActionRunner) parseFormError(ctx context.Context, err error) ActionResponse {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return NewRequestTooLargeResponse(ctx, err)
+	}
+	return NewServerErrorResponse(ctx, WrongRequestDecoding, err)
+}
+
+func fillRequestFiles(request any, files map[string][]*multipart.FileHeader) {
+	v := reflect.ValueOf(request)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup("file")
+		if !ok || !field.IsExported() || len(files[name]) == 0 {
+			continue
+		}
+		switch field.Type {
+		case fileHeaderType:
+			v.Field(i).Set(reflect.ValueOf(files[name][0]))
+		case fileHeadersType:
+			v.Field(i).Set(reflect.ValueOf(files[name]))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+type uploadTestRequest struct {
+	Title       string                  `qs:"title"`
+	Avatar      *multipart.FileHeader   `qs:"-" file:"avatar"`
+	Attachments []*multipart.FileHeader `qs:"-" file:"attachments"`
+}
+
+func newMultipartRequest(t *testing.T, fileContent string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.Nil(t, writer.WriteField("title", "Profile"))
+	for _, name := range []string{"avatar", "attachments", "attachments"} {
+		part, err := writer.CreateFormFile(name, name+".txt")
+		assert.Nil(t, err)
+		_, _ = part.Write([]byte(fileContent))
+	}
+	assert.Nil(t, writer.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+func newUploadRouter(t *testing.T, handled *uploadTestRequest) *DefaultRouter {
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Post(
+		"/upload",
+		Handle(runner, func(ctx context.Context, request *uploadTestRequest) (string, error) {
+			*handled = *request
+			file, err := request.Avatar.Open()
+			if err != nil {
+				return "", err
+			}
+			defer file.Close()
+			content, err := io.ReadAll(file)
+			return string(content), err
+		}),
+		UploadLimit(1024, 10),
+	)
+	router.AddRoutes(routes.GetRoutesInfo())
+	return router
+}
+
+func TestRunBindsMultipartForm(t *testing.T) {
+	var handled uploadTestRequest
+	router := newUploadRouter(t, &handled)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newMultipartRequest(t, "file content"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"file content"`, w.Body.String())
+	assert.Equal(t, "Profile", handled.Title)
+	assert.Equal(t, "avatar.txt", handled.Avatar.Filename)
+	assert.Len(t, handled.Attachments, 2)
+}
+
+func TestRunRemovesUploadedTempFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+	var handled uploadTestRequest
+	router := newUploadRouter(t, &handled)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newMultipartRequest(t, strings.Repeat("a", 60)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	entries, err := os.ReadDir(tempDir)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRunRejectsTooLargeBody(t *testing.T) {
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Post(
+		"/json",
+		Handle(runner, func(ctx context.Context, request *uploadTestRequest) (string, error) {
+			return "", nil
+		}),
+		UploadLimit(16, 0),
+	)
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	r := httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"title": "`+strings.Repeat("a", 64)+`"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestRunRejectsTooLargeUpload(t *testing.T) {
+	var handled uploadTestRequest
+	router := newUploadRouter(t, &handled)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newMultipartRequest(t, strings.Repeat("a", 2048)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestRunBindsUrlEncodedForm(t *testing.T) {
+	var handled uploadTestRequest
+	router := NewDefaultRouter(NewConfig()).(*DefaultRouter)
+	runner := newTestRunner(router)
+	routes := NewRoutes()
+	routes.Post("/form", Handle(runner, func(ctx context.Context, request *uploadTestRequest) (string, error) {
+		handled = *request
+		return "", nil
+	}))
+	router.AddRoutes(routes.GetRoutesInfo())
+
+	r := httptest.NewRequest(http.MethodPost, "/form", strings.NewReader("title=Profile"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Profile", handled.Title)
+}